@@ -3,11 +3,19 @@ package bootstrap
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/togglhire/backend-homework/config"
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/blob"
+	"github.com/togglhire/backend-homework/infrastructure/logging"
 	"github.com/togglhire/backend-homework/infrastructure/server"
 	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/telemetry"
 
 	"github.com/togglhire/backend-homework/usecase"
 )
@@ -16,18 +24,142 @@ type Closer interface {
 	Close() error
 }
 
+// resources are what CreateServerAndDependencies opens that must be closed, nil until opened.
+type resources struct {
+	tracing  Closer
+	database Closer
+	server   Closer
+}
+
+// closers lists the opened resources in the order they are opened: tracing, database, server.
+func (r resources) closers() []Closer {
+	var closers []Closer
+	for _, c := range []Closer{r.tracing, r.database, r.server} {
+		if c != nil {
+			closers = append(closers, c)
+		}
+	}
+	return closers
+}
+
+// CreateServerAndDependencies returns the resources it opened, in the order it opened them, even when it fails
+// halfway, so they can be closed.
 func CreateServerAndDependencies(cfg config.Config) (context.Context, *server.Server, []Closer, error) {
+	var opened resources
 
 	// INFRA
-	db := sql.SetupSQLConnection(cfg.DatabaseUrl)
-	repo := sql.NewRepo(db)
+	logger, err := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	slog.SetDefault(logger)
+
+	tracing, err := telemetry.NewTracing(context.Background(), telemetry.TracingConfig{
+		Endpoint:    cfg.TracingOTLPEndpoint,
+		ServiceName: cfg.TracingServiceName,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	tracing.Install()
+	opened.tracing = tracing
+
+	db := sql.SetupSQLConnection(cfg.DatabaseUrl, sql.NewLogger(logger, cfg.DatabaseSlowQueryThreshold))
+	conn, err := db.DB()
+	if err != nil {
+		return nil, nil, opened.closers(), err
+	}
+	opened.database = conn
+	if err := sql.RegisterTracing(db); err != nil {
+		return nil, nil, opened.closers(), err
+	}
+	repo := sql.NewRepo(db).WithQueryTimeout(cfg.DatabaseQueryTimeout)
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := sql.RegisterMetrics(db, registry); err != nil {
+		return nil, nil, opened.closers(), err
+	}
+	blobs, err := blob.NewLocalStore(cfg.AttachmentsDir)
+	if err != nil {
+		return nil, nil, opened.closers(), err
+	}
 
 	// USECASE
-	questions := usecase.NewQuestions(repo)
+	adaptiveConfig := usecase.AdaptiveConfig{
+		MinResponses:           cfg.IRTMinResponses,
+		MaxItems:               cfg.AdaptiveMaxItems,
+		StandardErrorThreshold: cfg.AdaptiveStandardErrorThreshold,
+	}
+	tenants := func(organization string, actor domain.Actor) server.Usecases {
+		scoped := repo.ForOrganization(organization).WithActor(actor)
+		attachments := usecase.NewAttachments(scoped, blobs, cfg.MaxAttachmentBytes)
+		responses := usecase.NewResponses(scoped, scoped)
+		rules := usecase.NewValidationRules(scoped, domain.DefaultRuleRegistry())
+		return server.Usecases{
+			Questions:   usecase.NewQuestions(scoped, scoped, attachments, rules),
+			Attachments: attachments,
+			Responses:   responses,
+			Generator:   usecase.NewGenerator(scoped),
+			Analytics:   usecase.NewAnalytics(scoped, scoped),
+			Adaptive:    usecase.NewAdaptive(scoped, responses, scoped, scoped, adaptiveConfig),
+			APIKeys:     usecase.NewAPIKeys(scoped),
+			Audit:       usecase.NewAudit(scoped),
+			Rules:       rules,
+		}
+	}
 
 	// SERVER
-	ctx, srv := server.NewServer(context.Background(), cfg.Port, questions)
-	return ctx, srv, []Closer{srv}, nil
+	auth := server.NewAuthenticator(cfg.AuthTokenSecret, server.Role(cfg.AnonymousRole), usecase.NewAPIKeys(repo))
+	limiter := server.NewRateLimiter(
+		server.RateLimit{Rate: cfg.RateLimitReadsPerSecond, Burst: cfg.RateLimitReadBurst},
+		server.RateLimit{Rate: cfg.RateLimitWritesPerSecond, Burst: cfg.RateLimitWriteBurst},
+		time.Now,
+	)
+	metrics, err := server.NewMetrics(registry, cfg.MetricsToken)
+	if err != nil {
+		return nil, nil, opened.closers(), err
+	}
+	health := sql.NewHealth(db)
+	readiness := server.NewReadiness(cfg.ReadinessTimeout, cfg.ReadinessDrainDelay,
+		server.Check{Name: "database", Run: health.Ping},
+		server.Check{Name: "migrations", Run: health.Migrations},
+		server.Check{Name: "database_writable", Run: health.Writable},
+		server.Check{Name: "attachments_writable", Run: blobs.Writable},
+	)
+	opts := server.Options{
+		Limiter:   limiter,
+		Metrics:   metrics,
+		Readiness: readiness,
+		Timeouts: server.Timeouts{
+			Read:       cfg.ReadTimeout,
+			ReadHeader: cfg.ReadHeaderTimeout,
+			Write:      cfg.WriteTimeout,
+			Idle:       cfg.IdleTimeout,
+			Shutdown:   cfg.ShutdownTimeout,
+		},
+		Limits: server.Limits{Body: cfg.MaxBodyBytes, Upload: cfg.MaxUploadBytes},
+	}
+	if cfg.TLSCertFile != "" || cfg.TLSKeyFile != "" {
+		opts.Certificates, err = server.NewCertificates(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSReloadInterval)
+		if err != nil {
+			return nil, nil, opened.closers(), err
+		}
+	}
+	ctx, srv := server.NewServer(context.Background(), cfg.Port, tenants, auth, opts)
+	opened.server = srv
+
+	if cfg.IRTCalibrationInterval > 0 {
+		go usecase.RunCalibration(ctx, cfg.IRTCalibrationInterval, repo, func(organization string) usecase.Adaptive {
+			return tenants(organization, domain.Actor{Subject: "calibration"}).Adaptive
+		})
+	}
+	if cfg.AttachmentSweepInterval > 0 {
+		go usecase.RunAttachmentSweep(ctx, cfg.AttachmentSweepInterval, cfg.AttachmentTTL, repo, func(organization string) usecase.Attachments {
+			return tenants(organization, domain.Actor{Subject: "attachment-sweep"}).Attachments
+		})
+	}
+	return ctx, srv, opened.closers(), nil
 }
 
 func Run() error {
@@ -44,12 +176,14 @@ func Run() error {
 	return nil
 }
 
+// closeResources closes the last opened first, the server stops answering before the database goes away and
+// spans are exported once nothing makes new ones.
 func closeResources(closers []Closer) {
-	log.Println("ending all resources gracefully...")
-	for _, closer := range closers {
-		err := closer.Close()
+	slog.Info("ending all resources gracefully...")
+	for i := len(closers) - 1; i >= 0; i-- {
+		err := closers[i].Close()
 		if err != nil {
-			log.Println("err ending resource:", err)
+			slog.Error("err ending resource", "err", err)
 		}
 	}
 }
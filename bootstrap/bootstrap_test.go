@@ -0,0 +1,65 @@
+package bootstrap
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type recordingCloser struct {
+	name   string
+	err    error
+	closed *[]string
+}
+
+func (c recordingCloser) Close() error {
+	*c.closed = append(*c.closed, c.name)
+	return c.err
+}
+
+func TestCloseResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		opened   func(closed *[]string) resources
+		expected []string
+	}{
+		{
+			name: "server drains before the database closes",
+			opened: func(closed *[]string) resources {
+				return resources{
+					tracing:  recordingCloser{name: "tracing", closed: closed},
+					database: recordingCloser{name: "database", err: errors.New("already closed"), closed: closed},
+					server:   recordingCloser{name: "server", closed: closed},
+				}
+			},
+			expected: []string{"server", "database", "tracing"},
+		},
+		{
+			name: "failed before the server was created",
+			opened: func(closed *[]string) resources {
+				return resources{
+					tracing:  recordingCloser{name: "tracing", closed: closed},
+					database: recordingCloser{name: "database", closed: closed},
+				}
+			},
+			expected: []string{"database", "tracing"},
+		},
+		{
+			name: "nothing opened",
+			opened: func(closed *[]string) resources {
+				return resources{}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var closed []string
+
+			closeResources(tt.opened(&closed).closers())
+
+			if !reflect.DeepEqual(closed, tt.expected) {
+				t.Errorf("resources were closed in order %v, expected %v", closed, tt.expected)
+			}
+		})
+	}
+}
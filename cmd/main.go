@@ -1,13 +1,15 @@
 package main
 
 import (
-	"log"
+	"log/slog"
+	"os"
 
 	"github.com/togglhire/backend-homework/bootstrap"
 )
 
 func main() {
 	if err := bootstrap.Run(); err != nil {
-		log.Fatal("app closed, reason: ", err)
+		slog.Error("app closed", "reason", err)
+		os.Exit(1)
 	}
 }
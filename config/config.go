@@ -1,20 +1,57 @@
 package config
 
 import (
-	"log"
+	"log/slog"
+	"os"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 type Config struct {
-	Port        int    `env:"PORT" envDefault:"3000"`
-	DatabaseUrl string `env:"DATABASE_URL" envDefault:"questions.db"`
+	Port                           int           `env:"PORT" envDefault:"3000"`
+	DatabaseUrl                    string        `env:"DATABASE_URL" envDefault:"sqlite://questions.db"`
+	DatabaseQueryTimeout           time.Duration `env:"DATABASE_QUERY_TIMEOUT" envDefault:"5s"`
+	DatabaseSlowQueryThreshold     time.Duration `env:"DATABASE_SLOW_QUERY_THRESHOLD" envDefault:"200ms"`
+	AttachmentsDir                 string        `env:"ATTACHMENTS_DIR" envDefault:"attachments"`
+	MaxAttachmentBytes             int64         `env:"MAX_ATTACHMENT_BYTES" envDefault:"5242880"`
+	AttachmentTTL                  time.Duration `env:"ATTACHMENT_TTL" envDefault:"24h"`
+	AttachmentSweepInterval        time.Duration `env:"ATTACHMENT_SWEEP_INTERVAL" envDefault:"1h"`
+	IRTMinResponses                int           `env:"IRT_MIN_RESPONSES" envDefault:"30"`
+	IRTCalibrationInterval         time.Duration `env:"IRT_CALIBRATION_INTERVAL" envDefault:"1h"`
+	AdaptiveMaxItems               int           `env:"ADAPTIVE_MAX_ITEMS" envDefault:"20"`
+	AdaptiveStandardErrorThreshold float64       `env:"ADAPTIVE_STANDARD_ERROR_THRESHOLD" envDefault:"0.3"`
+	AuthTokenSecret                string        `env:"AUTH_TOKEN_SECRET"`
+	AnonymousRole                  string        `env:"ANONYMOUS_ROLE"`
+	RateLimitReadsPerSecond        float64       `env:"RATE_LIMIT_READS_PER_SECOND" envDefault:"20"`
+	RateLimitReadBurst             int           `env:"RATE_LIMIT_READ_BURST" envDefault:"40"`
+	RateLimitWritesPerSecond       float64       `env:"RATE_LIMIT_WRITES_PER_SECOND" envDefault:"2"`
+	RateLimitWriteBurst            int           `env:"RATE_LIMIT_WRITE_BURST" envDefault:"10"`
+	MetricsToken                   string        `env:"METRICS_TOKEN"`
+	TracingOTLPEndpoint            string        `env:"TRACING_OTLP_ENDPOINT"`
+	TracingServiceName             string        `env:"TRACING_SERVICE_NAME" envDefault:"questions"`
+	TracingSampleRatio             float64       `env:"TRACING_SAMPLE_RATIO" envDefault:"1"`
+	ReadTimeout                    time.Duration `env:"READ_TIMEOUT" envDefault:"30s"`
+	ReadHeaderTimeout              time.Duration `env:"READ_HEADER_TIMEOUT" envDefault:"5s"`
+	WriteTimeout                   time.Duration `env:"WRITE_TIMEOUT" envDefault:"30s"`
+	IdleTimeout                    time.Duration `env:"IDLE_TIMEOUT" envDefault:"2m"`
+	ShutdownTimeout                time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"10s"`
+	MaxBodyBytes                   int64         `env:"MAX_BODY_BYTES" envDefault:"1048576"`
+	MaxUploadBytes                 int64         `env:"MAX_UPLOAD_BYTES" envDefault:"6291456"`
+	TLSCertFile                    string        `env:"TLS_CERT_FILE"`
+	TLSKeyFile                     string        `env:"TLS_KEY_FILE"`
+	TLSReloadInterval              time.Duration `env:"TLS_RELOAD_INTERVAL" envDefault:"1m"`
+	ReadinessTimeout               time.Duration `env:"READINESS_TIMEOUT" envDefault:"2s"`
+	ReadinessDrainDelay            time.Duration `env:"READINESS_DRAIN_DELAY" envDefault:"5s"`
+	LogLevel                       string        `env:"LOG_LEVEL" envDefault:"info"`
+	LogFormat                      string        `env:"LOG_FORMAT" envDefault:"json"`
 }
 
 func Parse() Config {
 	cfg := Config{}
 	if err := env.Parse(&cfg); err != nil {
-		log.Fatalf("%+v\n", err)
+		slog.Error("err parsing config", "err", err)
+		os.Exit(1)
 	}
 	return cfg
 }
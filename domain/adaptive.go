@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+var (
+	ErrNoSessionFound     = NewError(ErrNotFound, "not found adaptive session")
+	ErrSessionFinished    = NewError(ErrConflict, "adaptive session already finished")
+	ErrUnexpectedQuestion = NewError(ErrInvalid, "question is not the one asked by the adaptive session")
+	ErrNoCalibratedItems  = NewError(ErrConflict, "no calibrated questions available for adaptive sessions")
+)
+
+// ItemParameters are the 2PL parameters of a question, estimated from the responses stored so far.
+type ItemParameters struct {
+	QuestionID     int       `json:"question_id"`
+	Discrimination float64   `json:"discrimination"`
+	Difficulty     float64   `json:"difficulty"`
+	Responses      int       `json:"responses"`
+	CalibratedAt   time.Time `json:"calibrated_at"`
+}
+
+// AdaptiveSession asks questions one at a time, each one picked to be the most informative at the current
+// ability estimate, until the estimate is precise enough or the session runs out of items.
+type AdaptiveSession struct {
+	ID string `json:"id"`
+	// Candidate is also the one recorded in the responses, so the answers feed analytics and calibration
+	Candidate              string             `json:"candidate" validate:"required,max=255"`
+	Ability                float64            `json:"ability"`
+	StandardError          float64            `json:"standard_error"`
+	MaxItems               int                `json:"max_items" validate:"gte=0,lte=200"`
+	StandardErrorThreshold float64            `json:"standard_error_threshold" validate:"gte=0,lte=1"`
+	Items                  []AdaptiveItem     `json:"items"`
+	Finished               bool               `json:"finished"`
+	NextQuestionID         *int               `json:"-"`
+	Next                   *CandidateQuestion `json:"next,omitempty"`
+	CreatedAt              time.Time          `json:"created_at"`
+}
+
+type AdaptiveItem struct {
+	QuestionID int  `json:"question_id"`
+	Correct    bool `json:"correct"`
+}
+
+type AdaptiveRepository interface {
+	// SaveItemParameters replaces the parameters of every calibrated item.
+	SaveItemParameters([]ItemParameters) error
+	GetItemParameters() ([]ItemParameters, error)
+	AddSession(AdaptiveSession) error
+	GetSession(id string) (AdaptiveSession, error)
+	// AnswerSession adds the response and stores the new estimate and state of the session, along with the
+	// items not stored yet, in one transaction.
+	AnswerSession(AdaptiveSession, Response) (Response, error)
+}
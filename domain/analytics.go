@@ -0,0 +1,54 @@
+package domain
+
+// ItemCounts are the running totals kept for a question as responses come in.
+type ItemCounts struct {
+	QuestionID int
+	Responses  int
+	Correct    int
+	// Picks by option position
+	Picks map[int]int
+}
+
+// ItemScore is a single response to a question next to the overall record of the candidate who gave it.
+type ItemScore struct {
+	QuestionID         int
+	Correct            bool
+	CandidateResponses int
+	CandidateCorrect   int
+}
+
+type QuestionStats struct {
+	QuestionID int `json:"question_id"`
+	Responses  int `json:"responses"`
+	Correct    int `json:"correct"`
+	// PValue is the proportion of correct responses, nil until there are responses.
+	PValue *float64 `json:"p_value"`
+	// Discrimination is the point-biserial correlation between the item and the rest of the candidate
+	// results, nil while it can not be computed.
+	Discrimination *float64      `json:"discrimination"`
+	Options        []OptionStats `json:"options"`
+}
+
+type OptionStats struct {
+	Position    int     `json:"position"`
+	Body        string  `json:"body"`
+	Correct     bool    `json:"correct"`
+	Picks       int     `json:"picks"`
+	PickRate    float64 `json:"pick_rate"`
+	NeverChosen bool    `json:"never_chosen"`
+}
+
+type LibraryReport struct {
+	Questions int `json:"questions"`
+	Responses int `json:"responses"`
+	// MeanPValue averages the p-value of the questions that have responses.
+	MeanPValue *float64        `json:"mean_p_value"`
+	Items      []QuestionStats `json:"items"`
+}
+
+type AnalyticsRepository interface {
+	// GetItemCounts returns the counts of the given questions, of all of them when none is given.
+	GetItemCounts(questionIDs []int) ([]ItemCounts, error)
+	// GetItemScores returns the scores of the given questions, of all of them when none is given.
+	GetItemScores(questionIDs []int) ([]ItemScore, error)
+}
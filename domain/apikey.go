@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+var (
+	ErrNoAPIKeyFound = NewError(ErrNotFound, "not found api key")
+	ErrAPIKeyRevoked = NewError(ErrInvalid, "api key revoked")
+	ErrAPIKeyExpired = NewError(ErrInvalid, "api key expired")
+)
+
+// APIKey lets a machine client act on the questions of an organization within its scopes. Only a hash of
+// the secret is stored, the secret itself is shown once when the key is created.
+type APIKey struct {
+	ID           string     `json:"id"`
+	Organization string     `json:"-"`
+	Name         string     `json:"name" validate:"required,max=255"`
+	Prefix       string     `json:"prefix"`
+	Scopes       []string   `json:"scopes" validate:"required,min=1,dive,oneof=list create update delete publish export respond"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Expired tells whether the key can no longer be used at the given time.
+func (k APIKey) Expired(at time.Time) bool {
+	return k.ExpiresAt != nil && !at.Before(*k.ExpiresAt)
+}
+
+type APIKeyRepository interface {
+	AddAPIKey(key APIKey, hash string) error
+	GetAPIKeys() ([]APIKey, error)
+	RevokeAPIKey(id string, at time.Time) error
+	// FindAPIKey looks a key up by the hash of its secret in every organization, the organization of the
+	// request is only known once the key is found.
+	FindAPIKey(hash string) (APIKey, error)
+	TouchAPIKey(id string, at time.Time) error
+}
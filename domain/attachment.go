@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"io"
+	"time"
+)
+
+var (
+	ErrNoAttachmentFound        = NewError(ErrNotFound, "not found attachment")
+	ErrAttachmentTooLarge       = NewError(ErrInvalid, "attachment too large")
+	ErrAttachmentContentType    = NewError(ErrInvalid, "attachment content type not allowed")
+	AllowedAttachmentMediaTypes = []string{"image/png", "image/jpeg", "image/gif", "image/webp", "application/pdf"}
+)
+
+type Attachment struct {
+	ID          string    `json:"id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlobStore keeps the raw bytes of attachments, addressed by attachment id.
+type BlobStore interface {
+	Put(key string, r io.Reader) error
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+type AttachmentRepository interface {
+	AddAttachment(Attachment) error
+	GetAttachment(id string) (Attachment, error)
+	// DeleteUnreferencedAttachments removes the given attachments when no question or option points to them
+	// anymore, returning the ids actually removed.
+	DeleteUnreferencedAttachments(ids []string) ([]string, error)
+	// DeleteStaleAttachments removes the attachments created before cutoff that no question or option points
+	// to, returning the ids removed.
+	DeleteStaleAttachments(cutoff time.Time) ([]string, error)
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+	// AuditSaveTranslation and AuditDeleteTranslation record the translation itself in before and after.
+	AuditSaveTranslation   AuditAction = "save_translation"
+	AuditDeleteTranslation AuditAction = "delete_translation"
+)
+
+// Actor is who a change is made by, recorded with every audit entry.
+type Actor struct {
+	Subject   string
+	RequestID string
+	IP        string
+}
+
+// AuditEntry records one change of a question. Entries of an organization form a chain, each hash covers the
+// entry and the hash of the previous one, so editing or removing an entry breaks every later hash.
+type AuditEntry struct {
+	ID         int64           `json:"id"`
+	QuestionID int             `json:"question_id"`
+	Action     AuditAction     `json:"action"`
+	Actor      string          `json:"actor"`
+	RequestID  string          `json:"request_id,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// ComputeHash hashes every field but the id and the hash itself.
+func (e AuditEntry) ComputeHash() string {
+	fields := []string{
+		e.PrevHash,
+		strconv.Itoa(e.QuestionID),
+		string(e.Action),
+		e.Actor,
+		e.RequestID,
+		e.IP,
+		string(e.Before),
+		string(e.After),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+	h := sha256.New()
+	for _, field := range fields {
+		// length prefixes keep "ab"+"c" and "a"+"bc" apart
+		fmt.Fprintf(h, "%d:%s;", len(field), field)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type AuditFilter struct {
+	QuestionID *int
+	Actor      string
+	Action     AuditAction
+	From       *time.Time
+	To         *time.Time
+	// Cursor is the id of the last entry of the previous page, entries come newest first.
+	Cursor int64
+	Limit  int
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor *int64       `json:"next_cursor,omitempty"`
+}
+
+// AuditVerification is the outcome of walking the chain, BrokenAt is the first entry whose hash does not hold.
+type AuditVerification struct {
+	Entries  int    `json:"entries"`
+	Valid    bool   `json:"valid"`
+	BrokenAt *int64 `json:"broken_at,omitempty"`
+}
+
+type AuditRepository interface {
+	GetAuditEntries(ctx context.Context, filter AuditFilter) ([]AuditEntry, error)
+	// GetAuditChain returns every entry of the organization, oldest first.
+	GetAuditChain(ctx context.Context) ([]AuditEntry, error)
+}
+
+// ParseAuditAction accepts the known actions only.
+func ParseAuditAction(action string) (AuditAction, bool) {
+	switch a := AuditAction(strings.ToLower(action)); a {
+	case AuditCreate, AuditUpdate, AuditDelete, AuditSaveTranslation, AuditDeleteTranslation:
+		return a, true
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"errors"
+)
+
+// Kinds of failure, every error of the domain is one of them so callers can handle errors they do not know
+// of, errors.Is(err, ErrNotFound) holds for ErrNoQuestionFound.
+var (
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is a request clashing with the current state, trying again as is fails again.
+	ErrConflict = errors.New("conflict")
+	ErrInvalid  = errors.New("invalid")
+	// ErrUnavailable is a storage that can not be reached for now, trying again later may work.
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error is an error of a kind, it reads as the error it wraps.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// NewError returns an error of the kind reading as msg.
+func NewError(kind error, msg string) error {
+	return &Error{Kind: kind, Err: errors.New(msg)}
+}
+
+// WrapError marks err as of the kind, err stays in the chain.
+func WrapError(kind error, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
@@ -0,0 +1,70 @@
+package domain
+
+type FilterOperator string
+
+const (
+	FilterEqual          FilterOperator = "="
+	FilterNotEqual       FilterOperator = "!="
+	FilterLess           FilterOperator = "<"
+	FilterLessOrEqual    FilterOperator = "<="
+	FilterGreater        FilterOperator = ">"
+	FilterGreaterOrEqual FilterOperator = ">="
+	// FilterContains matches text containing the value, ignoring case.
+	FilterContains FilterOperator = "~"
+)
+
+// FilterKind is the type of the values a field is compared with, it decides the operators the field takes.
+type FilterKind string
+
+const (
+	FilterInteger    FilterKind = "integer"
+	FilterText       FilterKind = "text"
+	FilterBoolean    FilterKind = "boolean"
+	FilterTime       FilterKind = "time"
+	FilterDifficulty FilterKind = "difficulty"
+)
+
+var filterOperators = map[FilterKind][]FilterOperator{
+	FilterInteger:    {FilterEqual, FilterNotEqual, FilterLess, FilterLessOrEqual, FilterGreater, FilterGreaterOrEqual},
+	FilterText:       {FilterEqual, FilterNotEqual, FilterContains},
+	FilterBoolean:    {FilterEqual, FilterNotEqual},
+	FilterTime:       {FilterEqual, FilterNotEqual, FilterLess, FilterLessOrEqual, FilterGreater, FilterGreaterOrEqual},
+	FilterDifficulty: {FilterEqual, FilterNotEqual, FilterLess, FilterLessOrEqual, FilterGreater, FilterGreaterOrEqual},
+}
+
+func (k FilterKind) Operators() []FilterOperator {
+	return filterOperators[k]
+}
+
+func (k FilterKind) Allows(operator FilterOperator) bool {
+	for _, allowed := range filterOperators[k] {
+		if allowed == operator {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterableQuestionFields are the fields conditions can be put on. A tag condition holds when any of the
+// tags of the question matches, != when none does.
+var FilterableQuestionFields = map[string]FilterKind{
+	"id":                   FilterInteger,
+	"body":                 FilterText,
+	"explanation":          FilterText,
+	"tag":                  FilterText,
+	"difficulty":           FilterDifficulty,
+	"points":               FilterInteger,
+	"estimated_seconds":    FilterInteger,
+	"options_count":        FilterInteger,
+	"correct_count":        FilterInteger,
+	"has_multiple_correct": FilterBoolean,
+	"updated_at":           FilterTime,
+}
+
+// Condition compares a field with a value, the value is an int, a string, a bool, a time.Time or a
+// Difficulty following the kind of the field.
+type Condition struct {
+	Field    string
+	Operator FilterOperator
+	Value    interface{}
+}
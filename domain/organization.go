@@ -0,0 +1,21 @@
+package domain
+
+import "regexp"
+
+// DefaultOrganization owns the questions created before organizations existed and serves the requests
+// that name no organization.
+const DefaultOrganization = "default"
+
+var ErrInvalidOrganization = NewError(ErrInvalid, "invalid organization")
+
+var organizationPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// ValidOrganization tells whether id can name an organization: lowercase letters, digits, dashes and
+// underscores, at most 64 characters.
+func ValidOrganization(id string) bool {
+	return organizationPattern.MatchString(id)
+}
+
+type OrganizationRepository interface {
+	GetOrganizations() ([]string, error)
+}
@@ -1,24 +1,123 @@
 package domain
 
-import (
-	"fmt"
+import "context"
+
+var (
+	ErrNoQuestionFound = NewError(ErrNotFound, "not found question")
+	// ErrQuestionExists is adding a question under an id already taken in the organization.
+	ErrQuestionExists = NewError(ErrConflict, "question already exists")
+)
+
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyMedium Difficulty = "medium"
+	DifficultyHard   Difficulty = "hard"
 )
 
-var ErrNoQuestionFound = fmt.Errorf("not found question")
+var difficultyRanks = map[Difficulty]int{DifficultyEasy: 1, DifficultyMedium: 2, DifficultyHard: 3}
+
+// Rank places the difficulty on a numeric scale, 0 when the question has none.
+func (d Difficulty) Rank() int {
+	return difficultyRanks[d]
+}
+
+func DifficultyFromRank(rank int) Difficulty {
+	for difficulty, r := range difficultyRanks {
+		if r == rank {
+			return difficulty
+		}
+	}
+	return ""
+}
+
+// Reveal tells when candidates get to see the explanation and the feedback of the options.
+type Reveal string
+
+const (
+	RevealAfterSubmit Reveal = "after_submit"
+	RevealNever       Reveal = "never"
+)
 
 type Question struct {
-	ID      int      `json:"id" validate:"required"`
-	Body    string   `json:"body" validate:"required,min=1,max=255"`
-	Options []Option `json:"options" validate:"required,min=2,max=10,dive"`
+	ID               int        `json:"id" validate:"required"`
+	Body             string     `json:"body" validate:"required,min=1,max=255"`
+	Options          []Option   `json:"options" validate:"required,min=2,max=10,dive"`
+	Attachments      []string   `json:"attachments,omitempty" validate:"max=10,dive,required"`
+	Difficulty       Difficulty `json:"difficulty,omitempty" validate:"omitempty,oneof=easy medium hard"`
+	EstimatedSeconds int        `json:"estimated_seconds,omitempty" validate:"gte=0,lte=3600"`
+	Points           int        `json:"points,omitempty" validate:"gte=0,lte=1000"`
+	Explanation      string     `json:"explanation,omitempty" validate:"max=2000"`
+	Reveal           Reveal     `json:"reveal,omitempty" validate:"omitempty,oneof=after_submit never"`
+	Tags             []string   `json:"tags,omitempty" validate:"max=10,dive,required,max=50"`
 }
 
 type Option struct {
-	Body    string `json:"body" validate:"required,min=1,max=255"`
-	Correct bool   `json:"correct"`
+	Body        string   `json:"body" validate:"required,min=1,max=255"`
+	Correct     bool     `json:"correct"`
+	Attachments []string `json:"attachments,omitempty" validate:"max=10,dive,required"`
+	Feedback    string   `json:"feedback,omitempty" validate:"max=1000"`
+}
+
+// RevealsAfterSubmit tells if candidates see the explanation once they answered, the default when unset.
+func (q Question) RevealsAfterSubmit() bool {
+	return q.Reveal != RevealNever
+}
+
+// AttachmentIDs returns every attachment referenced by the question or any of its options.
+func (q Question) AttachmentIDs() []string {
+	ids := append([]string{}, q.Attachments...)
+	for _, opt := range q.Options {
+		ids = append(ids, opt.Attachments...)
+	}
+	return ids
+}
+
+// SortableQuestionFields are the fields questions can be listed by, on top of the default newest first order.
+var SortableQuestionFields = []string{"id", "difficulty", "estimated_seconds", "points", "updated_at"}
+
+func IsSortableQuestionField(field string) bool {
+	for _, f := range SortableQuestionFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// QuestionFilter narrows down a listing of questions, zero values do not filter. Questions must have all the
+// tags and meet all the conditions to be kept.
+type QuestionFilter struct {
+	IDs                 []int
+	Tags                []string
+	Difficulties        []Difficulty
+	MinPoints           *int
+	MaxPoints           *int
+	MinEstimatedSeconds *int
+	MaxEstimatedSeconds *int
+	Conditions          []Condition
+	Sort                []SortField
+}
+
+type Summary struct {
+	Questions             int `json:"questions"`
+	TotalEstimatedSeconds int `json:"total_estimated_seconds"`
+	TotalPoints           int `json:"total_points"`
 }
 
+// QuestionRepository stops working on a call once its context is done, returning the error of the context.
 type QuestionRepository interface {
-	GetAll() ([]Question, error)
-	Add(Question) error
-	Update(Question) error
+	GetAll(ctx context.Context) ([]Question, error)
+	Find(ctx context.Context, filter QuestionFilter) ([]Question, error)
+	Get(ctx context.Context, id int) (Question, error)
+	Add(ctx context.Context, question Question) error
+	// Update replaces a question and returns the version it replaced.
+	Update(ctx context.Context, question Question) (Question, error)
+	Delete(ctx context.Context, id int) error
 }
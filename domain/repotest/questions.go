@@ -0,0 +1,399 @@
+// Package repotest holds the behavior every implementation of the domain repositories must share.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// QuestionRepository runs the conformance suite of domain.QuestionRepository, newRepo returns an empty
+// repository for every subtest.
+func QuestionRepository(t *testing.T, newRepo func(t *testing.T) domain.QuestionRepository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo domain.QuestionRepository)
+	}{
+		{"round trip", testRoundTrip},
+		{"not found", testNotFound},
+		{"newest first", testNewestFirst},
+		{"sort", testSort},
+		{"filter", testFilter},
+		{"option order", testOptionOrder},
+		{"duplicate add", testDuplicateAdd},
+		{"delete", testDelete},
+		{"copies", testCopies},
+		{"canceled", testCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+func question(id int, body string, options ...domain.Option) domain.Question {
+	if len(options) == 0 {
+		options = []domain.Option{{Body: "yes", Correct: true}, {Body: "no"}}
+	}
+	return domain.Question{ID: id, Body: body, Options: options}
+}
+
+func mustAdd(t *testing.T, repo domain.QuestionRepository, questions ...domain.Question) {
+	t.Helper()
+	ctx := context.Background()
+	for _, q := range questions {
+		if err := repo.Add(ctx, q); err != nil {
+			t.Fatalf("err adding question %d: %s", q.ID, err)
+		}
+	}
+}
+
+func mustGet(t *testing.T, repo domain.QuestionRepository, id int) domain.Question {
+	t.Helper()
+	ctx := context.Background()
+	q, err := repo.Get(ctx, id)
+	if err != nil {
+		t.Fatalf("err getting question %d: %s", id, err)
+	}
+	return q
+}
+
+// listed returns the ids of every question, in the order GetAll lists them.
+func listed(t *testing.T, repo domain.QuestionRepository) []int {
+	t.Helper()
+	ctx := context.Background()
+	questions, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids(questions)
+}
+
+func found(t *testing.T, repo domain.QuestionRepository, filter domain.QuestionFilter) []int {
+	t.Helper()
+	ctx := context.Background()
+	questions, err := repo.Find(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids(questions)
+}
+
+func ids(questions []domain.Question) []int {
+	found := make([]int, 0, len(questions))
+	for _, q := range questions {
+		found = append(found, q.ID)
+	}
+	return found
+}
+
+func testRoundTrip(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	q := domain.Question{
+		ID:   1,
+		Body: "Where does the sun rise?",
+		Options: []domain.Option{
+			{Body: "West", Feedback: "It sets there"},
+			{Body: "East", Correct: true},
+		},
+		Difficulty:       domain.DifficultyMedium,
+		EstimatedSeconds: 30,
+		Points:           5,
+		Explanation:      "The earth spins eastwards",
+		Reveal:           domain.RevealNever,
+		Tags:             []string{"geography", "astronomy", "geography"},
+	}
+	mustAdd(t, repo, q)
+
+	expected := q
+	expected.Tags = []string{"astronomy", "geography"}
+	if got := mustGet(t, repo, 1); !reflect.DeepEqual(got, expected) {
+		t.Errorf("question returned, %+v, did not match %+v", got, expected)
+	}
+
+	q.Body = "Where does the sun set?"
+	q.Options = []domain.Option{{Body: "West", Correct: true}, {Body: "East"}}
+	q.Difficulty = ""
+	q.Tags = nil
+	previous, err := repo.Update(ctx, q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(previous, expected) {
+		t.Errorf("replaced question returned, %+v, did not match %+v", previous, expected)
+	}
+	if got := mustGet(t, repo, 1); !reflect.DeepEqual(got, q) {
+		t.Errorf("updated question returned, %+v, did not match %+v", got, q)
+	}
+}
+
+func testNotFound(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, question(1, "one"))
+
+	if _, err := repo.Get(ctx, 2); !errors.Is(err, domain.ErrNoQuestionFound) || !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("get of a missing question returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	if _, err := repo.Update(ctx, question(2, "two")); !errors.Is(err, domain.ErrNoQuestionFound) {
+		t.Errorf("update of a missing question returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	if err := repo.Delete(ctx, 2); !errors.Is(err, domain.ErrNoQuestionFound) {
+		t.Errorf("delete of a missing question returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	// a failed update does not create the question
+	if got := listed(t, repo); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("questions listed, %v, did not match %v", got, []int{1})
+	}
+}
+
+func testNewestFirst(t *testing.T, repo domain.QuestionRepository) {
+	if got := listed(t, repo); len(got) != 0 {
+		t.Errorf("empty repository listed %v", got)
+	}
+	mustAdd(t, repo, question(1, "one"), question(3, "three"), question(2, "two"))
+
+	for i := 0; i < 2; i++ {
+		if got := listed(t, repo); !reflect.DeepEqual(got, []int{3, 2, 1}) {
+			t.Errorf("questions listed, %v, did not match %v", got, []int{3, 2, 1})
+		}
+	}
+}
+
+func testSort(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	q1, q2, q3, q4 := question(1, "one"), question(2, "two"), question(3, "three"), question(4, "four")
+	q1.Points, q2.Points, q3.Points, q4.Points = 10, 5, 10, 1
+	q1.Difficulty, q2.Difficulty, q3.Difficulty, q4.Difficulty = domain.DifficultyHard, domain.DifficultyEasy, domain.DifficultyMedium, domain.DifficultyHard
+	mustAdd(t, repo, q1, q2, q3, q4)
+
+	tests := []struct {
+		name     string
+		sort     []domain.SortField
+		expected []int
+	}{
+		{"ascending, ties newest first", []domain.SortField{{Field: "points"}}, []int{4, 2, 3, 1}},
+		{"descending", []domain.SortField{{Field: "points", Desc: true}}, []int{3, 1, 2, 4}},
+		{"difficulty by rank", []domain.SortField{{Field: "difficulty"}, {Field: "id"}}, []int{2, 3, 1, 4}},
+		{"several fields", []domain.SortField{{Field: "difficulty", Desc: true}, {Field: "points"}}, []int{4, 1, 3, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := found(t, repo, domain.QuestionFilter{Sort: tt.sort})
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("questions listed, %v, did not match %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := repo.Find(ctx, domain.QuestionFilter{Sort: []domain.SortField{{Field: "body"}}}); err == nil {
+		t.Errorf("sorting by an unknown field did not fail")
+	}
+}
+
+func testFilter(t *testing.T, repo domain.QuestionRepository) {
+	q1 := question(1, "Where does the Sun rise?")
+	q1.Tags, q1.Difficulty, q1.Points, q1.EstimatedSeconds = []string{"astronomy", "easy"}, domain.DifficultyEasy, 1, 10
+	q2 := question(2, "Which are planets?", domain.Option{Body: "Mars", Correct: true}, domain.Option{Body: "Venus", Correct: true}, domain.Option{Body: "Sun"})
+	q2.Tags, q2.Difficulty, q2.Points, q2.EstimatedSeconds = []string{"astronomy"}, domain.DifficultyMedium, 5, 60
+	q3 := question(3, "Which keeps the sun on?", domain.Option{Body: "Fusion", Correct: true}, domain.Option{Body: "Fire"}, domain.Option{Body: "Coal"})
+	q3.Difficulty, q3.Points, q3.EstimatedSeconds = domain.DifficultyHard, 10, 120
+	mustAdd(t, repo, q1, q2, q3)
+
+	five, sixty := 5, 60
+	tests := []struct {
+		name     string
+		filter   domain.QuestionFilter
+		expected []int
+	}{
+		{"ids", domain.QuestionFilter{IDs: []int{1, 3, 7}}, []int{3, 1}},
+		{"all tags", domain.QuestionFilter{Tags: []string{"astronomy", "easy"}}, []int{1}},
+		{"difficulties", domain.QuestionFilter{Difficulties: []domain.Difficulty{domain.DifficultyEasy, domain.DifficultyHard}}, []int{3, 1}},
+		{"points", domain.QuestionFilter{MinPoints: &five, MaxPoints: &five}, []int{2}},
+		{"estimated seconds", domain.QuestionFilter{MinEstimatedSeconds: &sixty}, []int{3, 2}},
+		{"contains ignoring case", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "body", Operator: domain.FilterContains, Value: "SUN"},
+		}}, []int{3, 1}},
+		{"exact text", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "body", Operator: domain.FilterEqual, Value: "which are planets?"},
+		}}, []int{}},
+		{"counts", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "options_count", Operator: domain.FilterGreaterOrEqual, Value: 3},
+			{Field: "correct_count", Operator: domain.FilterEqual, Value: 1},
+		}}, []int{3}},
+		{"multiple correct", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "has_multiple_correct", Operator: domain.FilterNotEqual, Value: true},
+		}}, []int{3, 1}},
+		{"without tag", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "tag", Operator: domain.FilterNotEqual, Value: "easy"},
+		}}, []int{3, 2}},
+		{"tag contains", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "tag", Operator: domain.FilterContains, Value: "ASTRO"},
+		}}, []int{2, 1}},
+		{"difficulty by rank", domain.QuestionFilter{Conditions: []domain.Condition{
+			{Field: "difficulty", Operator: domain.FilterGreater, Value: domain.DifficultyEasy},
+		}}, []int{3, 2}},
+		{"nothing matches", domain.QuestionFilter{Tags: []string{"history"}}, []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := found(t, repo, tt.filter)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("questions listed, %v, did not match %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func testOptionOrder(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	options := []domain.Option{{Body: "c"}, {Body: "a", Correct: true}, {Body: "d"}, {Body: "b"}}
+	mustAdd(t, repo, question(1, "one", options...))
+	if got := mustGet(t, repo, 1).Options; !reflect.DeepEqual(got, options) {
+		t.Errorf("options returned, %+v, did not match %+v", got, options)
+	}
+
+	options = []domain.Option{{Body: "b"}, {Body: "d", Correct: true}, {Body: "a"}}
+	if _, err := repo.Update(ctx, question(1, "one", options...)); err != nil {
+		t.Fatal(err)
+	}
+	listed, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != 1 || !reflect.DeepEqual(listed[0].Options, options) {
+		t.Errorf("questions listed, %+v, did not have options %+v", listed, options)
+	}
+}
+
+func testDuplicateAdd(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	original := question(1, "one")
+	mustAdd(t, repo, original)
+
+	err := repo.Add(ctx, question(1, "again", domain.Option{Body: "x", Correct: true}, domain.Option{Body: "y"}, domain.Option{Body: "z"}))
+	if !errors.Is(err, domain.ErrQuestionExists) || !errors.Is(err, domain.ErrConflict) {
+		t.Fatalf("adding a question twice returned %v, expected %v", err, domain.ErrQuestionExists)
+	}
+	// nothing of the failed add is kept
+	if got := mustGet(t, repo, 1); !reflect.DeepEqual(got, original) {
+		t.Errorf("question returned, %+v, did not match %+v", got, original)
+	}
+	if got := listed(t, repo); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("questions listed, %v, did not match %v", got, []int{1})
+	}
+}
+
+func testDelete(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	mustAdd(t, repo, question(1, "one"), question(2, "two"))
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Get(ctx, 1); !errors.Is(err, domain.ErrNoQuestionFound) {
+		t.Errorf("get of a deleted question returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	if err := repo.Delete(ctx, 1); !errors.Is(err, domain.ErrNoQuestionFound) {
+		t.Errorf("second delete returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	if got := listed(t, repo); !reflect.DeepEqual(got, []int{2}) {
+		t.Errorf("questions listed, %v, did not match %v", got, []int{2})
+	}
+
+	// the id can be used again, without the options of the deleted question
+	readded := question(1, "one again", domain.Option{Body: "only", Correct: true}, domain.Option{Body: "other"})
+	mustAdd(t, repo, readded)
+	if got := mustGet(t, repo, 1); !reflect.DeepEqual(got, readded) {
+		t.Errorf("question returned, %+v, did not match %+v", got, readded)
+	}
+}
+
+func testCopies(t *testing.T, repo domain.QuestionRepository) {
+	q := question(1, "one")
+	q.Tags = []string{"a"}
+	mustAdd(t, repo, q)
+	q.Options[0].Body = "changed after add"
+	q.Tags[0] = "b"
+
+	got := mustGet(t, repo, 1)
+	if got.Options[0].Body != "yes" || got.Tags[0] != "a" {
+		t.Fatalf("question changed by its caller after add: %+v", got)
+	}
+	got.Options[0].Body = "changed after get"
+	if again := mustGet(t, repo, 1); again.Options[0].Body != "yes" {
+		t.Errorf("question changed by its caller after get: %+v", again)
+	}
+}
+
+func testCanceled(t *testing.T, repo domain.QuestionRepository) {
+	mustAdd(t, repo, question(1, "one"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := repo.GetAll(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("list with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	if _, err := repo.Get(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("get with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	if err := repo.Add(ctx, question(2, "two")); !errors.Is(err, context.Canceled) {
+		t.Errorf("add with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	if _, err := repo.Update(ctx, question(1, "changed")); !errors.Is(err, context.Canceled) {
+		t.Errorf("update with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	if err := repo.Delete(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("delete with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	// nothing was written
+	if got := listed(t, repo); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("questions listed, %v, did not match %v", got, []int{1})
+	}
+	if got := mustGet(t, repo, 1); got.Body != "one" {
+		t.Errorf("question changed by a canceled update: %+v", got)
+	}
+}
+
+// Concurrent checks that writes from several goroutines are all kept, for repositories promising to be safe
+// for concurrent use. Run it with -race.
+func Concurrent(t *testing.T, repo domain.QuestionRepository) {
+	ctx := context.Background()
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*3)
+	for i := 1; i <= writers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			errs <- repo.Add(ctx, question(id, "question"))
+			_, err := repo.Update(ctx, question(id, "updated"))
+			errs <- err
+			_, err = repo.GetAll(ctx)
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	listed, err := repo.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(listed) != writers {
+		t.Errorf("listed %d questions, expected %d", len(listed), writers)
+	}
+	for _, q := range listed {
+		if q.Body != "updated" {
+			t.Errorf("question %d was not updated: %+v", q.ID, q)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import "time"
+
+var (
+	ErrAlreadyAnswered  = NewError(ErrConflict, "question already answered by candidate")
+	ErrInvalidSelection = NewError(ErrInvalid, "selected option does not exist")
+	ErrNoResponseFound  = NewError(ErrNotFound, "not found response")
+)
+
+// Response is the answer a candidate submitted to a question, options are referenced by position.
+type Response struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	Candidate  string    `json:"candidate" validate:"required,max=255"`
+	Selected   []int     `json:"selected" validate:"required,min=1,max=10,dive,gte=0"`
+	Correct    bool      `json:"correct"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Grade tells if the selected options are exactly the correct ones of the question.
+func Grade(question Question, selected []int) (bool, error) {
+	chosen := make(map[int]bool, len(selected))
+	for _, position := range selected {
+		if position < 0 || position >= len(question.Options) {
+			return false, ErrInvalidSelection
+		}
+		chosen[position] = true
+	}
+	for i, opt := range question.Options {
+		if opt.Correct != chosen[i] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// CandidateQuestion is the view of a question given to candidates, without any hint of the answer until
+// they submitted and the question allows it.
+type CandidateQuestion struct {
+	ID          int               `json:"id"`
+	Body        string            `json:"body"`
+	Attachments []string          `json:"attachments,omitempty"`
+	Options     []CandidateOption `json:"options"`
+	Result      *Result           `json:"result,omitempty"`
+}
+
+type CandidateOption struct {
+	Body        string   `json:"body"`
+	Attachments []string `json:"attachments,omitempty"`
+}
+
+// Result is what a candidate gets to see about the answer once submitted.
+type Result struct {
+	ResponseID  int            `json:"response_id"`
+	Correct     *bool          `json:"correct,omitempty"`
+	Explanation string         `json:"explanation,omitempty"`
+	Options     []OptionResult `json:"options,omitempty"`
+}
+
+type OptionResult struct {
+	Body     string `json:"body"`
+	Correct  bool   `json:"correct"`
+	Selected bool   `json:"selected"`
+	Feedback string `json:"feedback,omitempty"`
+}
+
+func NewCandidateQuestion(question Question) CandidateQuestion {
+	options := make([]CandidateOption, 0, len(question.Options))
+	for _, opt := range question.Options {
+		options = append(options, CandidateOption{Body: opt.Body, Attachments: opt.Attachments})
+	}
+	return CandidateQuestion{ID: question.ID, Body: question.Body, Attachments: question.Attachments, Options: options}
+}
+
+// NewResult builds the result of a response, revealing the answer only when the question allows it.
+func NewResult(question Question, response Response) Result {
+	result := Result{ResponseID: response.ID}
+	if !question.RevealsAfterSubmit() {
+		return result
+	}
+
+	selected := make(map[int]bool, len(response.Selected))
+	for _, position := range response.Selected {
+		selected[position] = true
+	}
+
+	correct := response.Correct
+	result.Correct = &correct
+	result.Explanation = question.Explanation
+	for i, opt := range question.Options {
+		result.Options = append(result.Options, OptionResult{Body: opt.Body, Correct: opt.Correct, Selected: selected[i], Feedback: opt.Feedback})
+	}
+	return result
+}
+
+type ResponseRepository interface {
+	AddResponse(Response) (Response, error)
+	GetResponse(questionID int, candidate string) (Response, error)
+	GetResponses() ([]Response, error)
+	GetCandidateResponses(candidate string) ([]Response, error)
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidRuleSetting = NewError(ErrInvalid, "invalid validation rule")
+
+// Violation is a business rule a question breaks. Field is the json path of the offending field, Code names
+// the rule and Param is what the rule was configured with, if anything.
+type Violation struct {
+	Field string
+	Code  string
+	Param string
+}
+
+// Violations is every rule a question breaks, reported together.
+type Violations []Violation
+
+func (v Violations) Error() string {
+	rules := make([]string, 0, len(v))
+	for _, violation := range v {
+		rules = append(rules, violation.Field+": "+violation.Code)
+	}
+	return "question breaks rules, " + strings.Join(rules, ", ")
+}
+
+func (v Violations) Is(target error) bool {
+	return target == ErrInvalid
+}
+
+// Rule checks a question against something struct tags can not express.
+type Rule interface {
+	Check(question Question) []Violation
+}
+
+type RuleFunc func(question Question) []Violation
+
+func (f RuleFunc) Check(question Question) []Violation {
+	return f(question)
+}
+
+// Rules runs every rule and keeps all of their violations.
+type Rules []Rule
+
+func (rules Rules) Check(question Question) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		violations = append(violations, rule.Check(question)...)
+	}
+	return violations
+}
+
+// RuleSetting turns on a rule of the registry for an organization, Params configure it.
+type RuleSetting struct {
+	Name   string   `json:"name" validate:"required"`
+	Params []string `json:"params,omitempty" validate:"max=100,dive,required,max=100"`
+}
+
+type RuleRepository interface {
+	GetRuleSettings(ctx context.Context) ([]RuleSetting, error)
+	// SaveRuleSettings replaces every setting of the organization.
+	SaveRuleSettings(ctx context.Context, settings []RuleSetting) error
+}
+
+// RuleFactory builds a rule out of its params, refusing params it can not work with.
+type RuleFactory func(params []string) (Rule, error)
+
+// RuleRegistry names the rules organizations can pick from.
+type RuleRegistry map[string]RuleFactory
+
+// DefaultRuleRegistry holds the rules shipped with the service.
+func DefaultRuleRegistry() RuleRegistry {
+	return RuleRegistry{
+		"distinct_options": noParams(DistinctOptions()),
+		"single_answer":    noParams(SingleAnswer()),
+		"banned_words": func(params []string) (Rule, error) {
+			if len(params) == 0 {
+				return nil, fmt.Errorf("needs at least one word")
+			}
+			return BannedWords(params), nil
+		},
+	}
+}
+
+// Names returns the rules of the registry sorted.
+func (registry RuleRegistry) Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build composes the rules the settings turn on, in their order.
+func (registry RuleRegistry) Build(settings []RuleSetting) (Rules, error) {
+	rules := make(Rules, 0, len(settings))
+	for _, setting := range settings {
+		factory, ok := registry[setting.Name]
+		if !ok {
+			return nil, fmt.Errorf("%w %q: unknown rule", ErrInvalidRuleSetting, setting.Name)
+		}
+		rule, err := factory(setting.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %s", ErrInvalidRuleSetting, setting.Name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func noParams(rule Rule) RuleFactory {
+	return func(params []string) (Rule, error) {
+		if len(params) > 0 {
+			return nil, fmt.Errorf("takes no params")
+		}
+		return rule, nil
+	}
+}
+
+// OneCorrectOption applies to every organization, a question nobody can answer right is not a question.
+func OneCorrectOption() Rule {
+	return RuleFunc(func(question Question) []Violation {
+		for _, opt := range question.Options {
+			if opt.Correct {
+				return nil
+			}
+		}
+		return []Violation{{Field: "options", Code: "one_correct"}}
+	})
+}
+
+// DistinctOptions refuses options with the same body, ignoring case and surrounding spaces.
+func DistinctOptions() Rule {
+	return RuleFunc(func(question Question) []Violation {
+		var violations []Violation
+		seen := make(map[string]bool, len(question.Options))
+		for i, opt := range question.Options {
+			body := strings.ToLower(strings.TrimSpace(opt.Body))
+			if seen[body] {
+				violations = append(violations, Violation{Field: fmt.Sprintf("options[%d].body", i), Code: "distinct"})
+			}
+			seen[body] = true
+		}
+		return violations
+	})
+}
+
+// SingleAnswer refuses more than one correct option, along with OneCorrectOption questions have exactly one.
+func SingleAnswer() Rule {
+	return RuleFunc(func(question Question) []Violation {
+		correct := 0
+		for _, opt := range question.Options {
+			if opt.Correct {
+				correct++
+			}
+		}
+		if correct > 1 {
+			return []Violation{{Field: "options", Code: "single_answer"}}
+		}
+		return nil
+	})
+}
+
+// BannedWords refuses any text of the question containing one of the words as a whole word, ignoring case.
+func BannedWords(words []string) Rule {
+	banned := make(map[string]string, len(words))
+	for _, word := range words {
+		banned[strings.ToLower(word)] = word
+	}
+	check := func(field, text string) []Violation {
+		var violations []Violation
+		reported := make(map[string]bool)
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), notWordRune) {
+			if original, ok := banned[word]; ok && !reported[word] {
+				reported[word] = true
+				violations = append(violations, Violation{Field: field, Code: "banned_word", Param: original})
+			}
+		}
+		return violations
+	}
+	return RuleFunc(func(question Question) []Violation {
+		violations := check("body", question.Body)
+		violations = append(violations, check("explanation", question.Explanation)...)
+		for i, opt := range question.Options {
+			violations = append(violations, check(fmt.Sprintf("options[%d].body", i), opt.Body)...)
+			violations = append(violations, check(fmt.Sprintf("options[%d].feedback", i), opt.Feedback)...)
+		}
+		return violations
+	})
+}
+
+func notWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '-'
+}
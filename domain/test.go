@@ -0,0 +1,20 @@
+package domain
+
+var ErrUnsatisfiableConstraints = NewError(ErrInvalid, "not enough questions to satisfy the constraints")
+
+// TestSpec describes the test to generate. Difficulties are exact counts, the rest of the questions up to
+// Count are picked among the questions of any other difficulty.
+type TestSpec struct {
+	Count           int                `json:"count" validate:"required,min=1,max=200"`
+	Tags            []string           `json:"tags" validate:"max=10,dive,required"`
+	Difficulties    map[Difficulty]int `json:"difficulties" validate:"dive,keys,oneof=easy medium hard,endkeys,gte=0"`
+	MaxTotalSeconds int                `json:"max_total_seconds" validate:"gte=0"`
+	Seed            *int64             `json:"seed"`
+}
+
+type Test struct {
+	Seed                  int64      `json:"seed"`
+	Questions             []Question `json:"questions"`
+	TotalEstimatedSeconds int        `json:"total_estimated_seconds"`
+	TotalPoints           int        `json:"total_points"`
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "context"
+
+var (
+	ErrNoTranslationFound  = NewError(ErrNotFound, "not found translation")
+	ErrTranslationMismatch = NewError(ErrInvalid, "translation does not match the source question options")
+)
+
+type Translation struct {
+	Locale  string              `json:"locale" validate:"required,bcp47_language_tag"`
+	Body    string              `json:"body" validate:"required,min=1,max=255"`
+	Options []OptionTranslation `json:"options" validate:"required,min=2,max=10,dive"`
+}
+
+type OptionTranslation struct {
+	Body    string `json:"body" validate:"required,min=1,max=255"`
+	Correct bool   `json:"correct"`
+}
+
+// Matches tells if the translation keeps the shape of the source question, same amount of options and
+// same correct flags in the same positions.
+func (t Translation) Matches(question Question) bool {
+	if len(t.Options) != len(question.Options) {
+		return false
+	}
+	for i, opt := range t.Options {
+		if opt.Correct != question.Options[i].Correct {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns a copy of the question with the translated bodies.
+func (t Translation) Apply(question Question) Question {
+	translated := question
+	translated.Body = t.Body
+	translated.Options = make([]Option, len(question.Options))
+	for i, opt := range question.Options {
+		opt.Body = t.Options[i].Body
+		translated.Options[i] = opt
+	}
+	return translated
+}
+
+type TranslationRepository interface {
+	GetTranslations(ctx context.Context, questionID int) ([]Translation, error)
+	// GetTranslationsByLocales returns the translations of every question in any of the locales, by question id.
+	GetTranslationsByLocales(ctx context.Context, locales []string) (map[int][]Translation, error)
+	SaveTranslation(ctx context.Context, questionID int, translation Translation) error
+	DeleteTranslation(ctx context.Context, questionID int, locale string) error
+}
@@ -0,0 +1,96 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// LocalStore keeps blobs as plain files inside a directory of the local filesystem.
+type LocalStore struct {
+	dir string
+}
+
+func NewLocalStore(dir string) (LocalStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return LocalStore{}, fmt.Errorf("err creating blob dir:%w", err)
+	}
+	return LocalStore{dir: dir}, nil
+}
+
+func (s LocalStore) Put(key string, r io.Reader) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("err creating temp blob:%w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("err writing blob:%w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("err closing blob:%w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("err moving blob in place:%w", err)
+	}
+	return nil
+}
+
+func (s LocalStore) Get(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, domain.ErrNoAttachmentFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("err opening blob:%w", err)
+	}
+	return f, nil
+}
+
+func (s LocalStore) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("err deleting blob:%w", err)
+	}
+	return nil
+}
+
+func (s LocalStore) path(key string) (string, error) {
+	if key == "" || key != filepath.Base(key) || key[0] == '.' {
+		return "", fmt.Errorf("err invalid blob key %q", key)
+	}
+	return filepath.Join(s.dir, key), nil
+}
+
+// Writable tells if a blob can be written to the directory, by writing an empty one and removing it.
+func (s LocalStore) Writable(ctx context.Context) error {
+	tmp, err := os.CreateTemp(s.dir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("err writing to blob dir:%w", err)
+	}
+	_ = tmp.Close()
+	if err := os.Remove(tmp.Name()); err != nil {
+		return fmt.Errorf("err removing from blob dir:%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		level     string
+		format    string
+		expectErr bool
+	}{
+		{name: "json", level: "info", format: FormatJSON},
+		{name: "text", level: "DEBUG", format: FormatText},
+		{name: "unknown level", level: "loud", format: FormatJSON, expectErr: true},
+		{name: "unknown format", level: "info", format: "xml", expectErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(&bytes.Buffer{}, tt.level, tt.format)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("err returned, %v, expected an error %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestNew_context(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, "warn", FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-1"),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	logger.InfoContext(ctx, "below the level")
+	logger.With("question", 1).WarnContext(ctx, "slow")
+	logger.Error("no context")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("records logged, %d, did not match expected 2:\n%s", len(lines), buf.String())
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"level": "WARN", "msg": "slow", "question": float64(1), "request_id": "req-1",
+		"trace_id": traceID.String(), "span_id": spanID.String(),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("record has %s %v, expected %v", key, record[key], value)
+		}
+	}
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("record logged without a context names a request: %s", lines[1])
+	}
+}
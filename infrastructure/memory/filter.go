@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// keep tells if a question passes every part of the filter.
+func keep(stored storedQuestion, filter domain.QuestionFilter) (bool, error) {
+	question := stored.question
+	if len(filter.IDs) > 0 && !containsInt(filter.IDs, question.ID) {
+		return false, nil
+	}
+	for _, tag := range filter.Tags {
+		if !containsString(question.Tags, tag) {
+			return false, nil
+		}
+	}
+	if len(filter.Difficulties) > 0 && !containsDifficulty(filter.Difficulties, question.Difficulty) {
+		return false, nil
+	}
+	if filter.MinPoints != nil && question.Points < *filter.MinPoints {
+		return false, nil
+	}
+	if filter.MaxPoints != nil && question.Points > *filter.MaxPoints {
+		return false, nil
+	}
+	if filter.MinEstimatedSeconds != nil && question.EstimatedSeconds < *filter.MinEstimatedSeconds {
+		return false, nil
+	}
+	if filter.MaxEstimatedSeconds != nil && question.EstimatedSeconds > *filter.MaxEstimatedSeconds {
+		return false, nil
+	}
+	for _, condition := range filter.Conditions {
+		ok, err := meets(stored, condition)
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// meets evaluates a condition the way the sql repository does, text equality is exact and ~ ignores case.
+func meets(stored storedQuestion, condition domain.Condition) (bool, error) {
+	question := stored.question
+	switch condition.Field {
+	case "tag":
+		value, ok := condition.Value.(string)
+		if !ok {
+			return false, invalidValue(condition)
+		}
+		// != holds when no tag equals the value
+		operator := condition.Operator
+		if operator == domain.FilterNotEqual {
+			operator = domain.FilterEqual
+		}
+		var matched bool
+		for _, tag := range question.Tags {
+			matched = matched || textMatches(tag, operator, value)
+		}
+		if condition.Operator == domain.FilterNotEqual {
+			return !matched, nil
+		}
+		return matched, nil
+	case "body", "explanation":
+		value, ok := condition.Value.(string)
+		if !ok {
+			return false, invalidValue(condition)
+		}
+		text := question.Body
+		if condition.Field == "explanation" {
+			text = question.Explanation
+		}
+		return textMatches(text, condition.Operator, value), nil
+	case "has_multiple_correct":
+		value, ok := condition.Value.(bool)
+		if !ok {
+			return false, invalidValue(condition)
+		}
+		multiple := correctCount(question) > 1
+		return (multiple == value) == (condition.Operator == domain.FilterEqual), nil
+	case "updated_at":
+		value, ok := condition.Value.(time.Time)
+		if !ok {
+			return false, invalidValue(condition)
+		}
+		return compare(stored.updatedAt.UnixNano(), condition.Operator, value.UnixNano()), nil
+	case "difficulty":
+		value, ok := condition.Value.(domain.Difficulty)
+		if !ok {
+			return false, invalidValue(condition)
+		}
+		return compare(int64(question.Difficulty.Rank()), condition.Operator, int64(value.Rank())), nil
+	}
+
+	value, ok := condition.Value.(int)
+	if !ok {
+		return false, invalidValue(condition)
+	}
+	var field int
+	switch condition.Field {
+	case "id":
+		field = question.ID
+	case "points":
+		field = question.Points
+	case "estimated_seconds":
+		field = question.EstimatedSeconds
+	case "options_count":
+		field = len(question.Options)
+	case "correct_count":
+		field = correctCount(question)
+	default:
+		return false, fmt.Errorf("err filtering questions by %s %s", condition.Field, condition.Operator)
+	}
+	return compare(int64(field), condition.Operator, int64(value)), nil
+}
+
+func invalidValue(condition domain.Condition) error {
+	return fmt.Errorf("err filtering questions by %s %s: invalid value %v", condition.Field, condition.Operator, condition.Value)
+}
+
+func textMatches(text string, operator domain.FilterOperator, value string) bool {
+	switch operator {
+	case domain.FilterEqual:
+		return text == value
+	case domain.FilterNotEqual:
+		return text != value
+	case domain.FilterContains:
+		return strings.Contains(strings.ToLower(text), strings.ToLower(value))
+	}
+	return false
+}
+
+func compare(a int64, operator domain.FilterOperator, b int64) bool {
+	switch operator {
+	case domain.FilterEqual:
+		return a == b
+	case domain.FilterNotEqual:
+		return a != b
+	case domain.FilterLess:
+		return a < b
+	case domain.FilterLessOrEqual:
+		return a <= b
+	case domain.FilterGreater:
+		return a > b
+	case domain.FilterGreaterOrEqual:
+		return a >= b
+	}
+	return false
+}
+
+func correctCount(question domain.Question) int {
+	var count int
+	for _, opt := range question.Options {
+		if opt.Correct {
+			count++
+		}
+	}
+	return count
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDifficulty(values []domain.Difficulty, value domain.Difficulty) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+// Package memory keeps questions in memory, for tests and for trying the API out without a database.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// Repository is a domain.QuestionRepository safe for concurrent use. Questions are copied in and out, so
+// callers never share slices with what is stored. Calls fail with the error of their context once it is done.
+type Repository struct {
+	mu        sync.RWMutex
+	questions map[int]storedQuestion
+}
+
+type storedQuestion struct {
+	question  domain.Question
+	updatedAt time.Time
+}
+
+func NewRepo() *Repository {
+	return &Repository{questions: make(map[int]storedQuestion)}
+}
+
+func (r *Repository) GetAll(ctx context.Context) ([]domain.Question, error) {
+	return r.Find(ctx, domain.QuestionFilter{})
+}
+
+func (r *Repository) Find(ctx context.Context, filter domain.QuestionFilter) ([]domain.Question, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for _, field := range filter.Sort {
+		if !domain.IsSortableQuestionField(field.Field) {
+			return nil, fmt.Errorf("err sorting questions by unknown field %s", field.Field)
+		}
+	}
+	for _, condition := range filter.Conditions {
+		kind, ok := domain.FilterableQuestionFields[condition.Field]
+		if !ok || !kind.Allows(condition.Operator) {
+			return nil, fmt.Errorf("err filtering questions by %s %s", condition.Field, condition.Operator)
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	kept := make([]storedQuestion, 0, len(r.questions))
+	for _, stored := range r.questions {
+		ok, err := keep(stored, filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			kept = append(kept, stored)
+		}
+	}
+
+	sort.Slice(kept, func(i, j int) bool {
+		for _, field := range filter.Sort {
+			a, b := sortKey(kept[i], field.Field), sortKey(kept[j], field.Field)
+			if a == b {
+				continue
+			}
+			return (a < b) != field.Desc
+		}
+		// newest first keeps the order stable whatever the sort fields are
+		return kept[i].question.ID > kept[j].question.ID
+	})
+
+	questions := make([]domain.Question, 0, len(kept))
+	for _, stored := range kept {
+		questions = append(questions, copyQuestion(stored.question))
+	}
+	return questions, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id int) (domain.Question, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Question{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.questions[id]
+	if !ok {
+		return domain.Question{}, domain.ErrNoQuestionFound
+	}
+	return copyQuestion(stored.question), nil
+}
+
+func (r *Repository) Add(ctx context.Context, question domain.Question) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.questions[question.ID]; ok {
+		return domain.ErrQuestionExists
+	}
+	r.questions[question.ID] = storedQuestion{question: normalize(question), updatedAt: time.Now().UTC()}
+	return nil
+}
+
+func (r *Repository) Update(ctx context.Context, question domain.Question) (domain.Question, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.Question{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, ok := r.questions[question.ID]
+	if !ok {
+		return domain.Question{}, domain.ErrNoQuestionFound
+	}
+	r.questions[question.ID] = storedQuestion{question: normalize(question), updatedAt: time.Now().UTC()}
+	return copyQuestion(previous.question), nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.questions[id]; !ok {
+		return domain.ErrNoQuestionFound
+	}
+	delete(r.questions, id)
+	return nil
+}
+
+// normalize stores a question the way the sql repository reads it back, tags sorted without duplicates and
+// empty lists left out.
+func normalize(question domain.Question) domain.Question {
+	question = copyQuestion(question)
+	if question.Options == nil {
+		question.Options = []domain.Option{}
+	}
+
+	var tags []string
+	seen := make(map[string]bool, len(question.Tags))
+	for _, tag := range question.Tags {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	question.Tags = tags
+
+	question.Attachments = nilIfEmpty(question.Attachments)
+	for i := range question.Options {
+		question.Options[i].Attachments = nilIfEmpty(question.Options[i].Attachments)
+	}
+	return question
+}
+
+func copyQuestion(question domain.Question) domain.Question {
+	question.Attachments = copyStrings(question.Attachments)
+	question.Tags = copyStrings(question.Tags)
+	if question.Options != nil {
+		options := make([]domain.Option, len(question.Options))
+		for i, opt := range question.Options {
+			opt.Attachments = copyStrings(opt.Attachments)
+			options[i] = opt
+		}
+		question.Options = options
+	}
+	return question
+}
+
+func copyStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	return append([]string{}, values...)
+}
+
+func nilIfEmpty(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+func sortKey(stored storedQuestion, field string) int64 {
+	switch field {
+	case "id":
+		return int64(stored.question.ID)
+	case "difficulty":
+		return int64(stored.question.Difficulty.Rank())
+	case "estimated_seconds":
+		return int64(stored.question.EstimatedSeconds)
+	case "points":
+		return int64(stored.question.Points)
+	case "updated_at":
+		return stored.updatedAt.UnixNano()
+	}
+	return 0
+}
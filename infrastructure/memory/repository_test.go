@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/domain/repotest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.QuestionRepository(t, func(t *testing.T) domain.QuestionRepository {
+		return NewRepo()
+	})
+}
+
+func TestRepository_concurrent(t *testing.T) {
+	repotest.Concurrent(t, NewRepo())
+}
@@ -0,0 +1,35 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// logged writes an access log record for each request of route once it is answered. Server errors are logged
+// as errors and client errors as warnings, so a level of error or warn keeps only the failing requests.
+func logged(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		handler(recorder, r)
+
+		level := slog.LevelInfo
+		switch status := recorder.status(); {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		slog.Default().LogAttrs(r.Context(), level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status()),
+			slog.Int("bytes", recorder.written),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("ip", clientIP(r)),
+			slog.String("user_agent", r.UserAgent()),
+		)
+	}
+}
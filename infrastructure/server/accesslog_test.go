@@ -0,0 +1,86 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/logging"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+// captureLogs makes the default logger write JSON records to the returned buffer until the end of the test.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "debug", logging.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestServer_accessLog(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: []domain.Option{
+		{Body: "a", Correct: true}, {Body: "b"},
+	}})
+	srv := buildServer(repo, t)
+
+	tests := []struct {
+		name          string
+		requestID     string
+		path          string
+		expectedID    string
+		expectedLevel string
+		expectedCode  int
+	}{
+		{name: "id of the client is kept", requestID: "req-1", path: "/questions/7", expectedID: "req-1", expectedLevel: "WARN", expectedCode: http.StatusNotFound},
+		{name: "insane id is replaced", requestID: "not an id!", path: "/questions/1", expectedLevel: "INFO", expectedCode: http.StatusOK},
+		{name: "missing id is made up", path: "/questions/1", expectedLevel: "INFO", expectedCode: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				r.Header.Set(REQUEST_ID_HEADER, tt.requestID)
+			}
+			rr := httptest.NewRecorder()
+			withRequestID(logged("/questions/", srv.handleQuestion))(rr, r)
+
+			id := rr.Header().Get(REQUEST_ID_HEADER)
+			if tt.expectedID != "" && id != tt.expectedID {
+				t.Errorf("request id answered, %q, did not match expected %q", id, tt.expectedID)
+			}
+			if tt.expectedID == "" && (id == "" || id == tt.requestID) {
+				t.Errorf("request id answered, %q, was not made up", id)
+			}
+
+			var record map[string]interface{}
+			if err := json.Unmarshal(logs.Bytes(), &record); err != nil {
+				t.Fatalf("access log is not one JSON record: %s", logs.String())
+			}
+			expected := map[string]interface{}{
+				"msg": "request", "level": tt.expectedLevel, "request_id": id, "method": http.MethodGet,
+				"route": "/questions/", "path": tt.path, "status": float64(tt.expectedCode),
+			}
+			for key, value := range expected {
+				if record[key] != value {
+					t.Errorf("access log has %s %v, expected %v", key, record[key], value)
+				}
+			}
+			if _, ok := record["duration"]; !ok {
+				t.Errorf("access log has no duration: %v", record)
+			}
+		})
+	}
+}
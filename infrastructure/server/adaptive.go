@@ -0,0 +1,162 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+func (s Server) handleAdaptiveSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	s.startAdaptiveSession(w, r)
+}
+
+func (s Server) handleAdaptiveSession(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/adaptive-sessions/"), "/")
+	switch {
+	case len(segments) == 1 && r.Method == http.MethodGet:
+		s.getAdaptiveSession(w, r, segments[0])
+	case len(segments) == 2 && segments[1] == "answers" && r.Method == http.MethodPost:
+		s.answerAdaptiveSession(w, r, segments[0])
+	case len(segments) > 2 || (len(segments) == 2 && segments[1] != "answers"):
+		writeProblem(w, r, http.StatusNotFound, "")
+	default:
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+	}
+}
+
+func (s Server) startAdaptiveSession(w http.ResponseWriter, r *http.Request) {
+
+	var session domain.AdaptiveSession
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&session)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if s.identity.Subject == "" {
+		writeProblem(w, r, http.StatusUnauthorized, errUnauthenticated.Error())
+		return
+	}
+	var status int
+	session.Candidate, status, err = s.candidate(session.Candidate)
+	if err != nil {
+		writeProblem(w, r, status, err.Error())
+		return
+	}
+
+	if err := validate.Struct(session); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+
+	session, err = s.adaptive.Start(r.Context(), session)
+
+	if err != nil {
+		writeError(w, r, "Internal error starting adaptive session", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Location", "/adaptive-sessions/"+session.ID)
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(session)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response start adaptive session", "err", err)
+	}
+}
+
+func (s Server) getAdaptiveSession(w http.ResponseWriter, r *http.Request, id string) {
+
+	session, err := s.adaptive.Get(r.Context(), id, s.identity.Subject)
+
+	if err != nil {
+		writeError(w, r, "Internal error getting adaptive session", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(session)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response get adaptive session", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) answerAdaptiveSession(w http.ResponseWriter, r *http.Request, id string) {
+
+	var response domain.Response
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if err := validate.StructExcept(response, "Candidate"); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+	response.Candidate = s.identity.Subject
+
+	session, err := s.adaptive.Answer(r.Context(), id, response)
+
+	if err != nil {
+		writeError(w, r, "Internal error answering adaptive session", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(session)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response answer adaptive session", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) handleCalibrate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	parameters, err := s.adaptive.Calibrate(r.Context())
+	if err != nil {
+		writeError(w, r, "Internal error calibrating items", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(parameters)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response calibrate", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
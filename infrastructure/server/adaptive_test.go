@@ -0,0 +1,165 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_adaptiveSession(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+	srv.identity = Identity{Subject: "adaptive"}
+	other := *srv
+	other.identity = Identity{Subject: "mallory"}
+
+	start := func() *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/adaptive-sessions", bytes.NewBufferString(`{"candidate":"adaptive"}`))
+		req.Header.Add("Content-Type", "application/json")
+		srv.handleAdaptiveSessions(rr, req)
+		return rr
+	}
+
+	if rr := start(); rr.Result().StatusCode != http.StatusConflict {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusConflict)
+	}
+
+	for id := 1; id <= 8; id++ {
+		_ = repo.Add(context.Background(), domain.Question{ID: id, Body: fmt.Sprintf("question %d", id), Options: []domain.Option{
+			{Body: "right", Correct: true}, {Body: "wrong"},
+		}})
+	}
+	for c := 0; c < 12; c++ {
+		for id := 1; id <= 8; id++ {
+			selected := 1
+			if (c+id)%3 != 0 && c > id-2 {
+				selected = 0
+			}
+			response := domain.Response{QuestionID: id, Candidate: fmt.Sprintf("seed-%d", c), Selected: []int{selected}}
+			if _, err := srv.responses.Submit(context.Background(), response); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := srv.adaptive.Calibrate(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("calibration with a canceled context returned %v, expected %v", err, context.Canceled)
+	}
+	if saved, err := repo.GetItemParameters(); err != nil || len(saved) != 0 {
+		t.Errorf("canceled calibration saved %d items, %v, expected none", len(saved), err)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.handleCalibrate(rr, httptest.NewRequest(http.MethodPost, "/irt/calibrate", nil))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	var parameters []domain.ItemParameters
+	if err := json.Unmarshal(rr.Body.Bytes(), &parameters); err != nil {
+		t.Fatal(err)
+	}
+	if len(parameters) != 8 {
+		t.Fatalf("calibrated items returned, %d, did not match expected 8", len(parameters))
+	}
+
+	rr = start()
+	if rr.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusCreated)
+	}
+	var session domain.AdaptiveSession
+	if err := json.Unmarshal(rr.Body.Bytes(), &session); err != nil {
+		t.Fatal(err)
+	}
+	if session.Next == nil {
+		t.Fatal("expected a first question")
+	}
+
+	answerAs := func(srv Server, questionID int) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		body := fmt.Sprintf(`{"question_id":%d,"selected":[0]}`, questionID)
+		req := httptest.NewRequest(http.MethodPost, "/adaptive-sessions/"+session.ID+"/answers", bytes.NewBufferString(body))
+		req.Header.Add("Content-Type", "application/json")
+		srv.handleAdaptiveSession(rr, req)
+		return rr
+	}
+	answer := func(questionID int) *httptest.ResponseRecorder {
+		return answerAs(*srv, questionID)
+	}
+
+	if rr := answer(session.Next.ID + 100); rr.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusBadRequest)
+	}
+
+	// the session of a candidate is not found by others
+	if rr := answerAs(other, session.Next.ID); rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+	}
+	rr = httptest.NewRecorder()
+	other.handleAdaptiveSession(rr, httptest.NewRequest(http.MethodGet, "/adaptive-sessions/"+session.ID, nil))
+	if rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+	}
+
+	// the response is not kept when the session cannot be updated, so the question can be answered again
+	if err := db.Exec(`CREATE TRIGGER adaptive_session_fail BEFORE UPDATE ON adaptive_session BEGIN SELECT RAISE(ABORT, 'update failed'); END`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if rr := answer(session.Next.ID); rr.Result().StatusCode != http.StatusInternalServerError {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusInternalServerError)
+	}
+	if _, err := repo.GetResponse(session.Next.ID, "adaptive"); !errors.Is(err, domain.ErrNoResponseFound) {
+		t.Errorf("response of the failed answer returned %v, expected %v", err, domain.ErrNoResponseFound)
+	}
+	if err := db.Exec(`DROP TRIGGER adaptive_session_fail`).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	asked := map[int]bool{}
+	for !session.Finished {
+		if asked[session.Next.ID] {
+			t.Fatalf("question %d asked twice", session.Next.ID)
+		}
+		asked[session.Next.ID] = true
+		rr := answer(session.Next.ID)
+		if rr.Result().StatusCode != http.StatusOK {
+			t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+		}
+		session = domain.AdaptiveSession{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &session); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(session.Items) == 0 || len(session.Items) > 5 {
+		t.Errorf("answered items, %d, did not match expected 1 to 5", len(session.Items))
+	}
+	if session.Ability <= 0 {
+		t.Errorf("ability returned, %f, expected positive after only correct answers", session.Ability)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAdaptiveSession(rr, httptest.NewRequest(http.MethodGet, "/adaptive-sessions/"+session.ID, nil))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	if rr := answer(1); rr.Result().StatusCode != http.StatusConflict {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusConflict)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAdaptiveSession(rr, httptest.NewRequest(http.MethodGet, "/adaptive-sessions/missing", nil))
+	if rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+	}
+}
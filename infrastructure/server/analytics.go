@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) handleQuestionStats(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	stats, err := s.analytics.QuestionStats(r.Context(), id)
+
+	if err != nil {
+		writeError(w, r, "Internal error getting question stats", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(stats)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response question stats", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) handleLibraryReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	report, err := s.analytics.Report(r.Context())
+	if err != nil {
+		writeError(w, r, "Internal error getting library report", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(report)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response library report", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
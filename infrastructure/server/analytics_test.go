@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_questionStats(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: []domain.Option{
+		{Body: "a", Correct: true}, {Body: "b"}, {Body: "c"},
+	}})
+	for _, id := range []int{2, 3} {
+		_ = repo.Add(context.Background(), domain.Question{ID: id, Body: "other", Options: []domain.Option{
+			{Body: "x", Correct: true}, {Body: "y"},
+		}})
+	}
+	_ = repo.Add(context.Background(), domain.Question{ID: 4, Body: "unanswered", Options: []domain.Option{
+		{Body: "x", Correct: true}, {Body: "y"},
+	}})
+
+	for _, candidate := range []string{"strong-1", "strong-2"} {
+		for _, id := range []int{1, 2, 3} {
+			if _, err := srv.responses.Submit(context.Background(), domain.Response{QuestionID: id, Candidate: candidate, Selected: []int{0}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	for _, candidate := range []string{"weak-1", "weak-2"} {
+		for _, id := range []int{1, 2, 3} {
+			if _, err := srv.responses.Submit(context.Background(), domain.Response{QuestionID: id, Candidate: candidate, Selected: []int{1}}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/stats", nil))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	var stats domain.QuestionStats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Responses != 4 || stats.Correct != 2 || stats.PValue == nil || *stats.PValue != 0.5 {
+		t.Errorf("p-value stats returned, %+v, did not match expected 2 correct out of 4", stats)
+	}
+	if stats.Discrimination == nil || math.Abs(*stats.Discrimination-1) > 1e-9 {
+		t.Errorf("discrimination returned, %v, did not match expected 1", stats.Discrimination)
+	}
+	expectedPicks := []int{2, 2, 0}
+	for i, opt := range stats.Options {
+		if opt.Picks != expectedPicks[i] {
+			t.Errorf("picks of option %d, %d, did not match expected %d", i, opt.Picks, expectedPicks[i])
+		}
+		if opt.NeverChosen != (expectedPicks[i] == 0) {
+			t.Errorf("never chosen flag of option %d should be %t", i, expectedPicks[i] == 0)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/5/stats", nil))
+	if rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/stats", nil))
+	var report domain.LibraryReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Questions != 4 || report.Responses != 12 || report.MeanPValue == nil || *report.MeanPValue != 0.5 {
+		t.Errorf("library report returned, %+v, did not match expected totals", report)
+	}
+	for _, item := range report.Items {
+		if item.QuestionID == 4 && (item.PValue != nil || item.Discrimination != nil || item.Options[0].NeverChosen) {
+			t.Errorf("unanswered question should not have stats, got %+v", item)
+		}
+	}
+
+	if err := srv.questions.Delete(context.Background(), 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := srv.responses.Submit(context.Background(), domain.Response{QuestionID: 4, Candidate: "weak-1", Selected: []int{0}}); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := srv.analytics.QuestionStats(context.Background(), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Responses != 1 || stats.Correct != 1 {
+		t.Errorf("stats should be updated as responses come in, got %+v", stats)
+	}
+}
+
+func TestServer_questionStatsAfterUpdate(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	question := domain.Question{ID: 1, Body: "one", Options: []domain.Option{{Body: "a", Correct: true}, {Body: "b"}}}
+	_ = repo.Add(context.Background(), question)
+	for candidate, selected := range map[string]int{"ann": 0, "bob": 1} {
+		if _, err := srv.responses.Submit(context.Background(), domain.Response{QuestionID: 1, Candidate: candidate, Selected: []int{selected}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reworded := question
+	reworded.Body = "one, reworded"
+	reworded.Options = []domain.Option{{Body: "a", Correct: true, Feedback: "right"}, {Body: "b"}}
+	swapped := reworded
+	swapped.Options = []domain.Option{{Body: "b", Correct: true}, {Body: "a"}}
+
+	tests := []struct {
+		name              string
+		question          domain.Question
+		expectedResponses int
+		expectedPicks     []int
+	}{
+		{name: "same options should keep the stats", question: reworded, expectedResponses: 2, expectedPicks: []int{1, 1}},
+		{name: "changed options should reset the stats", question: swapped, expectedResponses: 0, expectedPicks: []int{0, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/questions", buildBufJson(tt.question, t))
+			r.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.handleQuestions(rr, r)
+			if rr.Result().StatusCode != http.StatusOK {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+			}
+
+			rr = httptest.NewRecorder()
+			srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/stats", nil))
+			var stats domain.QuestionStats
+			if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+				t.Fatal(err)
+			}
+			if stats.Responses != tt.expectedResponses {
+				t.Errorf("responses returned, %d, did not match expected %d", stats.Responses, tt.expectedResponses)
+			}
+			for i, opt := range stats.Options {
+				if opt.Picks != tt.expectedPicks[i] {
+					t.Errorf("picks of option %d, %d, did not match expected %d", i, opt.Picks, tt.expectedPicks[i])
+				}
+			}
+		})
+	}
+
+	if _, err := srv.responses.Submit(context.Background(), domain.Response{QuestionID: 1, Candidate: "ann", Selected: []int{0}}); err != nil {
+		t.Errorf("candidate should answer the changed question again, got %v", err)
+	}
+}
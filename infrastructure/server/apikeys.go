@@ -0,0 +1,108 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// createdAPIKey is the only response carrying the secret of a key.
+type createdAPIKey struct {
+	domain.APIKey
+	Key string `json:"key"`
+}
+
+func (s Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.listAPIKeys(w, r)
+	case http.MethodPost:
+		s.createAPIKey(w, r)
+	default:
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+	}
+}
+
+func (s Server) handleAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	s.revokeAPIKey(w, r, strings.TrimPrefix(r.URL.Path, "/api-keys/"))
+}
+
+func (s Server) listAPIKeys(w http.ResponseWriter, r *http.Request) {
+
+	keys, err := s.apiKeys.List()
+	if err != nil {
+		writeError(w, r, "Internal error listing api keys", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(keys)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response list api keys", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) createAPIKey(w http.ResponseWriter, r *http.Request) {
+
+	var key domain.APIKey
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&key)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if err := validate.Struct(key); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+	if key.Expired(time.Now()) {
+		writeProblem(w, r, http.StatusBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	key, secret, err := s.apiKeys.Create(key)
+	if err != nil {
+		writeError(w, r, "Internal error creating api key", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(createdAPIKey{APIKey: key, Key: secret})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response create api key", "err", err)
+	}
+}
+
+func (s Server) revokeAPIKey(w http.ResponseWriter, r *http.Request, id string) {
+
+	err := s.apiKeys.Revoke(id)
+
+	if err != nil {
+		writeError(w, r, "Internal error revoking api key", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
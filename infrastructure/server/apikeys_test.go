@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/usecase"
+)
+
+func createAPIKey(srv *Server, body string, t *testing.T) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBufferString(body))
+	r.Header.Add("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.handleAPIKeys(rr, r)
+	return rr
+}
+
+func TestServer_createAPIKey(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{name: "valid key should be created", body: `{"name":"ats","scopes":["list","create"]}`, expectedStatus: http.StatusCreated},
+		{name: "key with expiry should be created", body: `{"name":"ats","scopes":["list"],"expires_at":"2999-01-01T00:00:00Z"}`, expectedStatus: http.StatusCreated},
+		{name: "missing name should fail with 400", body: `{"scopes":["list"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing scopes should fail with 400", body: `{"name":"ats"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown scope should fail with 400", body: `{"name":"ats","scopes":["manage_keys"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "past expiry should fail with 400", body: `{"name":"ats","scopes":["list"],"expires_at":"2000-01-01T00:00:00Z"}`, expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := createAPIKey(srv, tt.body, t)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestServer_apiKeyAuthentication(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+	srv.auth = NewAuthenticator(TEST_TOKEN_SECRET, "", srv.auth.keys)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "keyed", Options: []domain.Option{{Body: "a", Correct: true}}})
+
+	rr := createAPIKey(srv, `{"name":"ats","scopes":["list"]}`, t)
+	var created createdAPIKey
+	if err := json.Unmarshal(rr.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.Key, created.Prefix) || created.Prefix == created.Key {
+		t.Fatalf("prefix returned, %q, should only be the start of the key %q", created.Prefix, created.Key)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	expired := "qk_expired"
+	if err := repo.AddAPIKey(domain.APIKey{ID: "expired", Name: "old", Scopes: []string{"list"}, CreatedAt: past, ExpiresAt: &past}, usecase.HashAPIKey(expired)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		header         string
+		value          string
+		method         string
+		expectedStatus int
+	}{
+		{name: "bearer key should list", header: "Authorization", value: "Bearer " + created.Key, method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "header key should list", header: API_KEY_HEADER, value: created.Key, method: http.MethodGet, expectedStatus: http.StatusOK},
+		{name: "key without the scope should throw 403", header: API_KEY_HEADER, value: created.Key, method: http.MethodDelete, expectedStatus: http.StatusForbidden},
+		{name: "unknown key should throw 401", header: API_KEY_HEADER, value: "qk_unknown", method: http.MethodGet, expectedStatus: http.StatusUnauthorized},
+		{name: "expired key should throw 401", header: "Authorization", value: "Bearer " + expired, method: http.MethodGet, expectedStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/questions/1", nil)
+			r.Header.Add(tt.header, tt.value)
+			rr := httptest.NewRecorder()
+			srv.guard(questionPermission, Server.handleQuestion)(rr, r)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/api-keys", nil)
+	r.Header.Add(API_KEY_HEADER, created.Key)
+	rr = httptest.NewRecorder()
+	srv.guard(always(PermissionManageKeys), Server.handleAPIKeys)(rr, r)
+	if rr.Result().StatusCode != http.StatusForbidden {
+		t.Errorf("Status code returned, %d, did not match expected code %d for a key managing keys", rr.Result().StatusCode, http.StatusForbidden)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAPIKeys(rr, httptest.NewRequest(http.MethodGet, "/api-keys", nil))
+	if strings.Contains(rr.Body.String(), created.Key) {
+		t.Errorf("listing api keys leaked the secret")
+	}
+	var keys []domain.APIKey
+	if err := json.Unmarshal(rr.Body.Bytes(), &keys); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 2 || keys[1].ID != created.ID || keys[1].LastUsedAt == nil {
+		t.Errorf("keys listed, %+v, should hold the created key with its last use", keys)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAPIKey(rr, httptest.NewRequest(http.MethodDelete, "/api-keys/"+created.ID, nil))
+	if rr.Result().StatusCode != http.StatusNoContent {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNoContent)
+	}
+	r = httptest.NewRequest(http.MethodGet, "/questions/1", nil)
+	r.Header.Add(API_KEY_HEADER, created.Key)
+	rr = httptest.NewRecorder()
+	srv.guard(questionPermission, Server.handleQuestion)(rr, r)
+	if rr.Result().StatusCode != http.StatusUnauthorized {
+		t.Errorf("Status code returned, %d, did not match expected code %d for a revoked key", rr.Result().StatusCode, http.StatusUnauthorized)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAPIKey(rr, httptest.NewRequest(http.MethodDelete, "/api-keys/missing", nil))
+	if rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// attachments never change once uploaded, so clients can cache them forever. They belong to an organization and
+// need authentication, so shared caches must not keep them.
+const ATTACHMENT_CACHE_CONTROL = "private, max-age=31536000, immutable"
+
+func (s Server) handleAttachments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	s.uploadAttachment(w, r)
+}
+
+func (s Server) handleAttachment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	s.downloadAttachment(w, r, strings.TrimPrefix(r.URL.Path, "/attachments/"))
+}
+
+func (s Server) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type, expected multipart/form-data")
+		return
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			writeProblem(w, r, http.StatusBadRequest, "missing file part")
+			return
+		}
+		if err != nil {
+			writeBodyError(w, r, "failed to read multipart body", err)
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		attachment, err := s.attachments.Upload(part.FileName(), part)
+		switch {
+		case errors.Is(err, domain.ErrAttachmentTooLarge):
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, err.Error())
+			return
+		case errors.Is(err, domain.ErrAttachmentContentType):
+			writeProblem(w, r, http.StatusUnsupportedMediaType, fmt.Sprintf("%s, allowed: %s", err, strings.Join(domain.AllowedAttachmentMediaTypes, ", ")))
+			return
+		case err != nil:
+			writeError(w, r, "Internal error uploading attachment", err)
+			return
+		}
+
+		w.Header().Add("Content-Type", "application/json")
+		w.Header().Add("Location", "/attachments/"+attachment.ID)
+		w.WriteHeader(http.StatusCreated)
+
+		err = json.NewEncoder(w).Encode(attachment)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "err encoding json response upload attachment", "err", err)
+		}
+		return
+	}
+}
+
+func (s Server) downloadAttachment(w http.ResponseWriter, r *http.Request, id string) {
+
+	attachment, content, err := s.attachments.Open(id)
+	if err != nil {
+		writeError(w, r, "Internal error downloading attachment", err)
+		return
+	}
+	defer content.Close()
+
+	etag := strconv.Quote(attachment.ID)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", ATTACHMENT_CACHE_CONTROL)
+	w.Header().Set("Last-Modified", attachment.CreatedAt.UTC().Format(http.TimeFormat))
+
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		slog.ErrorContext(r.Context(), "err writing attachment response", "err", err)
+	}
+}
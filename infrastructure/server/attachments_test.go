@@ -0,0 +1,197 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func buildMultipart(content []byte, t *testing.T) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "chart.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := part.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body, writer.FormDataContentType()
+}
+
+func buildPNG(t *testing.T) []byte {
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func uploadAttachment(srv *Server, content []byte, t *testing.T) *httptest.ResponseRecorder {
+	body, contentType := buildMultipart(content, t)
+	r := httptest.NewRequest(http.MethodPost, "/attachments", body)
+	r.Header.Set("Content-Type", contentType)
+	rr := httptest.NewRecorder()
+	srv.handleAttachments(rr, r)
+	return rr
+}
+
+func TestServer_uploadAttachment(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name           string
+		content        []byte
+		expectedStatus int
+	}{
+		{name: "valid png should be created", content: buildPNG(t), expectedStatus: http.StatusCreated},
+		{name: "plain text should fail with 415", content: []byte("just some text"), expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "too large file should fail with 413", content: append(buildPNG(t), make([]byte, 2048)...), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := uploadAttachment(srv, tt.content, t)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestServer_downloadAttachmentAndCleanup(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	content := buildPNG(t)
+	rr := uploadAttachment(srv, content, t)
+	var attachment domain.Attachment
+	if err := json.Unmarshal(rr.Body.Bytes(), &attachment); err != nil {
+		t.Fatal(err)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAttachment(rr, httptest.NewRequest(http.MethodGet, "/attachments/"+attachment.ID, nil))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	if !bytes.Equal(rr.Body.Bytes(), content) {
+		t.Errorf("downloaded content did not match uploaded content")
+	}
+	if got := rr.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("content type returned, %s, did not match expected image/png", got)
+	}
+	if got := rr.Header().Get("Cache-Control"); got != "private, max-age=31536000, immutable" {
+		t.Errorf("cache control returned, %s, did not match expected private, max-age=31536000, immutable", got)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/attachments/"+attachment.ID, nil)
+	r.Header.Set("If-None-Match", rr.Header().Get("ETag"))
+	rr = httptest.NewRecorder()
+	srv.handleAttachment(rr, r)
+	if rr.Result().StatusCode != http.StatusNotModified {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotModified)
+	}
+
+	question := domain.Question{ID: 1, Body: "what does this chart show?", Attachments: []string{attachment.ID},
+		Options: []domain.Option{{Body: "growth", Correct: true}, {Body: "decline", Attachments: []string{attachment.ID}}}}
+	r = httptest.NewRequest(http.MethodPost, "/questions", buildBufJson(question, t))
+	r.Header.Add("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	srv.addQuestion(rr, r)
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+
+	got, err := srv.questions.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Attachments) != 1 || len(got.Options[1].Attachments) != 1 {
+		t.Errorf("attachments were not stored with the question, got %+v", got)
+	}
+
+	question.ID = 2
+	question.Attachments = []string{"unknown"}
+	r = httptest.NewRequest(http.MethodPost, "/questions", buildBufJson(question, t))
+	r.Header.Add("Content-Type", "application/json")
+	rr = httptest.NewRecorder()
+	srv.addQuestion(rr, r)
+	if rr.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusBadRequest)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodDelete, "/questions/1", nil))
+	if rr.Result().StatusCode != http.StatusNoContent {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNoContent)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleAttachment(rr, httptest.NewRequest(http.MethodGet, "/attachments/"+attachment.ID, nil))
+	if rr.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("orphan attachment should be removed, status code returned %d", rr.Result().StatusCode)
+	}
+}
+
+func TestServer_sweepAttachments(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	upload := func() domain.Attachment {
+		var attachment domain.Attachment
+		if err := json.Unmarshal(uploadAttachment(srv, buildPNG(t), t).Body.Bytes(), &attachment); err != nil {
+			t.Fatal(err)
+		}
+		return attachment
+	}
+	referenced, orphan := upload(), upload()
+	question := domain.Question{ID: 1, Body: "what does this chart show?", Attachments: []string{referenced.ID},
+		Options: []domain.Option{{Body: "growth", Correct: true}, {Body: "decline"}}}
+	if err := srv.questions.Add(context.Background(), question); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name             string
+		ttl              time.Duration
+		expectedSwept    int
+		expectedStatuses map[string]int
+	}{
+		{name: "recent orphans should be kept", ttl: time.Hour, expectedSwept: 0,
+			expectedStatuses: map[string]int{referenced.ID: http.StatusOK, orphan.ID: http.StatusOK}},
+		{name: "expired orphans should be removed", ttl: 0, expectedSwept: 1,
+			expectedStatuses: map[string]int{referenced.ID: http.StatusOK, orphan.ID: http.StatusNotFound}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			swept, err := srv.attachments.Sweep(tt.ttl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if swept != tt.expectedSwept {
+				t.Errorf("attachments swept, %d, did not match expected %d", swept, tt.expectedSwept)
+			}
+			for id, expected := range tt.expectedStatuses {
+				rr := httptest.NewRecorder()
+				srv.handleAttachment(rr, httptest.NewRequest(http.MethodGet, "/attachments/"+id, nil))
+				if rr.Result().StatusCode != expected {
+					t.Errorf("Status code returned for %s, %d, did not match expected code %d", id, rr.Result().StatusCode, expected)
+				}
+			}
+		})
+	}
+}
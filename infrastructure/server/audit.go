@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+)
+
+func (s Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := s.audit.List(r.Context(), filter)
+	if err != nil {
+		writeError(w, r, "Internal error listing audit entries", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(page)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response list audit entries", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) handleAuditVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	verification, err := s.audit.Verify(r.Context())
+	if err != nil {
+		writeError(w, r, "Internal error verifying audit chain", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(verification)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response verify audit chain", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_audit(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	token, err := srv.auth.Issue(Claims{Subject: "ann", Organization: domain.DefaultOrganization, Roles: []Role{RoleAdmin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+		r.Header.Add("Authorization", "Bearer "+token)
+		r.Header.Add(REQUEST_ID_HEADER, "req-1")
+		rr := httptest.NewRecorder()
+		handler(rr, r)
+		return rr
+	}
+	questions := srv.guard(questionPermission, Server.handleQuestions)
+
+	question := domain.Question{ID: 1, Body: "first", Options: []domain.Option{{Body: "a", Correct: true}, {Body: "b"}}}
+	send(questions, organizationRequest(t, http.MethodPost, "/questions", "", buildBufJson(question, t)))
+	question.Body = "second"
+	send(questions, organizationRequest(t, http.MethodPut, "/questions", "", buildBufJson(question, t)))
+	send(srv.guard(questionPermission, Server.handleQuestion), httptest.NewRequest(http.MethodDelete, "/questions/1", nil))
+
+	list := func(query string) domain.AuditPage {
+		rr := send(srv.guard(always(PermissionAudit), Server.handleAudit), httptest.NewRequest(http.MethodGet, "/audit"+query, nil))
+		if rr.Result().StatusCode != http.StatusOK {
+			t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+		}
+		var page domain.AuditPage
+		if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		return page
+	}
+
+	page := list("")
+	if len(page.Entries) != 3 {
+		t.Fatalf("audit entries returned, %d, did not match expected 3", len(page.Entries))
+	}
+	update := page.Entries[1]
+	if update.Action != domain.AuditUpdate || update.Actor != "ann" || update.RequestID != "req-1" || update.IP == "" {
+		t.Errorf("update entry returned, %+v, did not record the action, actor, request and ip", update)
+	}
+	var before, after domain.Question
+	_ = json.Unmarshal(update.Before, &before)
+	_ = json.Unmarshal(update.After, &after)
+	if before.Body != "first" || after.Body != "second" {
+		t.Errorf("update entry recorded body %q to %q, expected first to second", before.Body, after.Body)
+	}
+	if page.Entries[0].Action != domain.AuditDelete || page.Entries[0].After != nil {
+		t.Errorf("delete entry returned, %+v, expected a delete without after", page.Entries[0])
+	}
+
+	filtered := list("?action=create")
+	if len(filtered.Entries) != 1 || filtered.Entries[0].Action != domain.AuditCreate {
+		t.Errorf("entries filtered by action returned, %+v, expected the create only", filtered.Entries)
+	}
+
+	first := list("?limit=2")
+	if len(first.Entries) != 2 || first.NextCursor == nil {
+		t.Fatalf("first page returned, %+v, expected 2 entries and a cursor", first)
+	}
+	second := list("?limit=2&cursor=" + jsonInt(*first.NextCursor))
+	if len(second.Entries) != 1 || second.NextCursor != nil || second.Entries[0].Action != domain.AuditCreate {
+		t.Errorf("second page returned, %+v, expected the create entry and no cursor", second)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.handleAudit(rr, httptest.NewRequest(http.MethodGet, "/audit?action=rename", nil))
+	if rr.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusBadRequest)
+	}
+
+	verify := func() domain.AuditVerification {
+		rr := httptest.NewRecorder()
+		srv.handleAuditVerify(rr, httptest.NewRequest(http.MethodGet, "/audit/verify", nil))
+		var verification domain.AuditVerification
+		if err := json.Unmarshal(rr.Body.Bytes(), &verification); err != nil {
+			t.Fatal(err)
+		}
+		return verification
+	}
+	if v := verify(); !v.Valid || v.Entries != 3 {
+		t.Errorf("verification returned, %+v, expected a valid chain of 3", v)
+	}
+
+	if err := db.Exec(`UPDATE audit_log SET actor = 'mallory' where id = ?`, update.ID).Error; err == nil {
+		t.Errorf("updating the audit log should be refused")
+	}
+	if err := db.Exec(`DROP TRIGGER audit_log_no_update`).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec(`UPDATE audit_log SET actor = 'mallory' where id = ?`, update.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if v := verify(); v.Valid || v.BrokenAt == nil || *v.BrokenAt != update.ID {
+		t.Errorf("verification returned, %+v, expected the chain broken at %d", v, update.ID)
+	}
+}
+
+func TestServer_auditTranslations(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	questions := srv.guard(questionPermission, Server.handleQuestions)
+	question := srv.guard(questionPermission, Server.handleQuestion)
+	send := func(handler http.HandlerFunc, r *http.Request) {
+		rr := httptest.NewRecorder()
+		handler(rr, r)
+		if rr.Code >= http.StatusBadRequest {
+			t.Fatalf("Status code returned, %d, for %s %s: %s", rr.Code, r.Method, r.URL.Path, rr.Body.String())
+		}
+	}
+
+	send(questions, organizationRequest(t, http.MethodPost, "/questions", "acme", buildBufJson(domain.Question{ID: 1, Body: "Where does the sun set?",
+		Options: []domain.Option{{Body: "East"}, {Body: "West", Correct: true}}}, t)))
+	for _, body := range []string{"¿Dónde se pone el sol?", "¿Por dónde se pone el sol?"} {
+		translation := domain.Translation{Body: body, Options: []domain.OptionTranslation{{Body: "Este"}, {Body: "Oeste", Correct: true}}}
+		send(question, organizationRequest(t, http.MethodPut, "/questions/1/translations/es", "acme", buildTranslationJson(translation, t)))
+	}
+	send(question, organizationRequest(t, http.MethodDelete, "/questions/1/translations/es", "acme", nil))
+
+	rr := httptest.NewRecorder()
+	srv.guard(always(PermissionAudit), Server.handleAudit)(rr, organizationRequest(t, http.MethodGet, "/audit", "acme", nil))
+	var page domain.AuditPage
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Entries) != 4 {
+		t.Fatalf("audit entries returned, %d, did not match expected 4", len(page.Entries))
+	}
+
+	bodies := func(entry domain.AuditEntry) (string, string) {
+		var before, after domain.Translation
+		_ = json.Unmarshal(entry.Before, &before)
+		_ = json.Unmarshal(entry.After, &after)
+		return before.Body, after.Body
+	}
+	tests := []struct {
+		entry          domain.AuditEntry
+		expectedAction domain.AuditAction
+		expectedBefore string
+		expectedAfter  string
+	}{
+		{page.Entries[2], domain.AuditSaveTranslation, "", "¿Dónde se pone el sol?"},
+		{page.Entries[1], domain.AuditSaveTranslation, "¿Dónde se pone el sol?", "¿Por dónde se pone el sol?"},
+		{page.Entries[0], domain.AuditDeleteTranslation, "¿Por dónde se pone el sol?", ""},
+	}
+	for _, tt := range tests {
+		before, after := bodies(tt.entry)
+		if tt.entry.Action != tt.expectedAction || tt.entry.QuestionID != 1 || before != tt.expectedBefore || after != tt.expectedAfter {
+			t.Errorf("entry returned, %s of %d from %q to %q, did not match %s from %q to %q",
+				tt.entry.Action, tt.entry.QuestionID, before, after, tt.expectedAction, tt.expectedBefore, tt.expectedAfter)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	srv.guard(always(PermissionAudit), Server.handleAuditVerify)(rr, organizationRequest(t, http.MethodGet, "/audit/verify", "acme", nil))
+	var verification domain.AuditVerification
+	if err := json.Unmarshal(rr.Body.Bytes(), &verification); err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid || verification.Entries != 4 {
+		t.Errorf("verification returned, %+v, expected a valid chain of 4", verification)
+	}
+}
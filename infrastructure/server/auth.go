@@ -0,0 +1,198 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/usecase"
+)
+
+const (
+	ORGANIZATION_HEADER = "X-Org"
+	API_KEY_HEADER      = "X-API-Key"
+)
+
+var (
+	errUnauthenticated       = errors.New("authentication required")
+	errInvalidToken          = errors.New("invalid token")
+	errExpiredToken          = errors.New("expired token")
+	errOrganizationDenied    = errors.New("token does not grant access to this organization")
+	errManyCredentials       = errors.New("send either an authorization header or an api key")
+	errAnonymousOrganization = errors.New("authentication required to select an organization")
+)
+
+// Claims is what the server reads from a bearer token.
+type Claims struct {
+	Subject      string `json:"sub"`
+	Organization string `json:"org"`
+	Roles        []Role `json:"roles,omitempty"`
+	ExpiresAt    int64  `json:"exp,omitempty"`
+}
+
+// Identity is who a request acts as, anonymous requests have no subject. Users are granted permissions
+// through their roles, api keys through their scopes.
+type Identity struct {
+	Subject      string
+	Organization string
+	Roles        []Role
+	Scopes       []Permission
+	APIKey       bool
+}
+
+func (i Identity) Can(permission Permission) bool {
+	if !i.APIKey {
+		return Allowed(i.Roles, permission)
+	}
+	for _, scope := range i.Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator verifies HS256 signed JWTs and api keys, without a secret no JWT is accepted. Requests
+// without credentials get the anonymous role, none when it is empty.
+type Authenticator struct {
+	secret    []byte
+	anonymous Role
+	keys      usecase.APIKeys
+	now       func() time.Time
+}
+
+func NewAuthenticator(secret string, anonymous Role, keys usecase.APIKeys) Authenticator {
+	return Authenticator{secret: []byte(secret), anonymous: anonymous, keys: keys, now: time.Now}
+}
+
+func (a Authenticator) Verify(token string) (Claims, error) {
+	if len(a.secret) == 0 {
+		return Claims{}, errInvalidToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, errInvalidToken
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return Claims{}, errInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, a.sign(parts[0]+"."+parts[1])) {
+		return Claims{}, errInvalidToken
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, errInvalidToken
+	}
+	if !domain.ValidOrganization(claims.Organization) {
+		return Claims{}, errInvalidToken
+	}
+	if claims.ExpiresAt != 0 && a.now().Unix() >= claims.ExpiresAt {
+		return Claims{}, errExpiredToken
+	}
+	return claims, nil
+}
+
+// Issue signs claims into a token, meant for tests and tooling, tokens are issued by the identity provider.
+func (a Authenticator) Issue(claims Claims) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "HS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(a.sign(unsigned)), nil
+}
+
+func (a Authenticator) sign(unsigned string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// identify resolves who the request acts as. The organization is the one of the bearer token or api key, a
+// header naming another organization is refused. Requests without credentials act on the default
+// organization, a header naming another one is refused as well, else anyone could reach any library.
+func (a Authenticator) identify(r *http.Request) (Identity, int, error) {
+	header := r.Header.Get(ORGANIZATION_HEADER)
+	authorization := r.Header.Get("Authorization")
+	apiKey := r.Header.Get(API_KEY_HEADER)
+
+	if authorization != "" && apiKey != "" {
+		return Identity{}, http.StatusBadRequest, errManyCredentials
+	}
+	if authorization != "" {
+		if !strings.HasPrefix(authorization, "Bearer ") {
+			return Identity{}, http.StatusUnauthorized, errInvalidToken
+		}
+		token := strings.TrimPrefix(authorization, "Bearer ")
+		if usecase.IsAPIKey(token) {
+			apiKey = token
+		} else {
+			claims, err := a.Verify(token)
+			if err != nil {
+				return Identity{}, http.StatusUnauthorized, err
+			}
+			return bindOrganization(Identity{Subject: claims.Subject, Organization: claims.Organization, Roles: claims.Roles}, header)
+		}
+	}
+	if apiKey != "" {
+		key, err := a.keys.Authenticate(apiKey)
+		switch {
+		case errors.Is(err, domain.ErrNoAPIKeyFound), errors.Is(err, domain.ErrAPIKeyRevoked), errors.Is(err, domain.ErrAPIKeyExpired):
+			return Identity{}, http.StatusUnauthorized, err
+		case err != nil:
+			slog.ErrorContext(r.Context(), "err authenticating api key", "err", err)
+			return Identity{}, http.StatusInternalServerError, errors.New("internal error authenticating api key")
+		}
+		scopes := make([]Permission, 0, len(key.Scopes))
+		for _, scope := range key.Scopes {
+			scopes = append(scopes, Permission(scope))
+		}
+		return bindOrganization(Identity{Subject: "api-key:" + key.ID, Organization: key.Organization, Scopes: scopes, APIKey: true}, header)
+	}
+
+	if header != "" && !domain.ValidOrganization(header) {
+		return Identity{}, http.StatusBadRequest, domain.ErrInvalidOrganization
+	}
+	if header != "" && header != domain.DefaultOrganization {
+		return Identity{}, http.StatusUnauthorized, errAnonymousOrganization
+	}
+	identity := Identity{Organization: domain.DefaultOrganization}
+	if a.anonymous != "" {
+		identity.Roles = []Role{a.anonymous}
+	}
+	return identity, http.StatusOK, nil
+}
+
+// bindOrganization refuses an X-Org header naming another organization than the credentials.
+func bindOrganization(identity Identity, header string) (Identity, int, error) {
+	if header != "" && header != identity.Organization {
+		return Identity{}, http.StatusForbidden, errOrganizationDenied
+	}
+	return identity, http.StatusOK, nil
+}
@@ -0,0 +1,236 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+const (
+	MAX_FILTER_LENGTH     = 1000
+	MAX_FILTER_CONDITIONS = 20
+)
+
+// filterError points at where a filter stops making sense, positions count bytes from 0.
+type filterError struct {
+	pos int
+	msg string
+}
+
+func (e filterError) Error() string {
+	return fmt.Sprintf("invalid filter at position %d: %s", e.pos, e.msg)
+}
+
+// parseFilterExpression reads a filter query param, conditions joined by &, for example
+//
+//	body~"sun" & options_count>=3 & has_multiple_correct=true
+//
+// In a URL the & is escaped as %26, ?filter=body~"sun"%26options_count>=3, or left as it is, see
+// parseQuestionFilter.
+// Fields must be in domain.FilterableQuestionFields. Values are integers, true or false, easy, medium or
+// hard, RFC 3339 times or text, text is double quoted unless it is a single word, \" and \\ escape.
+func parseFilterExpression(expression string) ([]domain.Condition, error) {
+	if len(expression) > MAX_FILTER_LENGTH {
+		return nil, filterError{pos: MAX_FILTER_LENGTH, msg: fmt.Sprintf("filter is longer than %d bytes", MAX_FILTER_LENGTH)}
+	}
+	p := filterParser{input: expression}
+	var conditions []domain.Condition
+	for {
+		p.skipSpaces()
+		if len(conditions) == MAX_FILTER_CONDITIONS {
+			return nil, filterError{pos: p.pos, msg: fmt.Sprintf("filter has more than %d conditions", MAX_FILTER_CONDITIONS)}
+		}
+		condition, err := p.condition()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+
+		p.skipSpaces()
+		if p.done() {
+			return conditions, nil
+		}
+		if p.input[p.pos] != '&' {
+			return nil, filterError{pos: p.pos, msg: fmt.Sprintf("expected & between conditions, got %q", p.input[p.pos])}
+		}
+		p.pos++
+	}
+}
+
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *filterParser) skipSpaces() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *filterParser) condition() (domain.Condition, error) {
+	start := p.pos
+	field := p.field()
+	if field == "" {
+		if p.done() {
+			return domain.Condition{}, filterError{pos: p.pos, msg: "expected a field, got the end of the filter"}
+		}
+		return domain.Condition{}, filterError{pos: p.pos, msg: fmt.Sprintf("expected a field, got %q", p.input[p.pos])}
+	}
+	kind, ok := domain.FilterableQuestionFields[field]
+	if !ok {
+		return domain.Condition{}, filterError{pos: start, msg: fmt.Sprintf("unknown field %q, expected one of %s", field, strings.Join(filterableFields(), ", "))}
+	}
+
+	p.skipSpaces()
+	opStart := p.pos
+	operator := p.operator()
+	if operator == "" {
+		return domain.Condition{}, filterError{pos: p.pos, msg: fmt.Sprintf("expected an operator after %s", field)}
+	}
+	if !kind.Allows(operator) {
+		return domain.Condition{}, filterError{pos: opStart, msg: fmt.Sprintf("operator %s does not apply to %s, expected one of %s", operator, field, joinOperators(kind.Operators()))}
+	}
+
+	p.skipSpaces()
+	valueStart := p.pos
+	raw, quoted, err := p.value()
+	if err != nil {
+		return domain.Condition{}, err
+	}
+	value, err := filterValue(kind, raw, quoted)
+	if err != nil {
+		return domain.Condition{}, filterError{pos: valueStart, msg: fmt.Sprintf("%s of %s", err, field)}
+	}
+	return domain.Condition{Field: field, Operator: operator, Value: value}, nil
+}
+
+func (p *filterParser) field() string {
+	start := p.pos
+	for !p.done() {
+		c := p.input[p.pos]
+		if !(c >= 'a' && c <= 'z' || c == '_' || p.pos > start && c >= '0' && c <= '9') {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// operator reads the longest operator, <= before <.
+func (p *filterParser) operator() domain.FilterOperator {
+	for _, operator := range []domain.FilterOperator{
+		domain.FilterNotEqual, domain.FilterLessOrEqual, domain.FilterGreaterOrEqual,
+		domain.FilterEqual, domain.FilterLess, domain.FilterGreater, domain.FilterContains,
+	} {
+		if strings.HasPrefix(p.input[p.pos:], string(operator)) {
+			p.pos += len(operator)
+			return operator
+		}
+	}
+	return ""
+}
+
+// value reads a quoted string or a bare word running up to the next space or &.
+func (p *filterParser) value() (string, bool, error) {
+	if p.done() {
+		return "", false, filterError{pos: p.pos, msg: "expected a value, got the end of the filter"}
+	}
+	if p.input[p.pos] != '"' {
+		start := p.pos
+		for !p.done() && p.input[p.pos] != ' ' && p.input[p.pos] != '\t' && p.input[p.pos] != '&' {
+			if p.input[p.pos] == '"' {
+				return "", false, filterError{pos: p.pos, msg: "unexpected quote in a value, quote the whole value"}
+			}
+			p.pos++
+		}
+		if p.pos == start {
+			return "", false, filterError{pos: p.pos, msg: fmt.Sprintf("expected a value, got %q", p.input[p.pos])}
+		}
+		return p.input[start:p.pos], false, nil
+	}
+
+	start := p.pos
+	p.pos++
+	var value strings.Builder
+	for !p.done() {
+		c := p.input[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return value.String(), true, nil
+		case c == '\\':
+			if p.pos+1 >= len(p.input) || (p.input[p.pos+1] != '"' && p.input[p.pos+1] != '\\') {
+				return "", false, filterError{pos: p.pos, msg: `invalid escape, expected \" or \\`}
+			}
+			value.WriteByte(p.input[p.pos+1])
+			p.pos += 2
+		default:
+			value.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", false, filterError{pos: start, msg: "unterminated string"}
+}
+
+// filterValue converts a value to the kind of its field, only text may be quoted.
+func filterValue(kind domain.FilterKind, raw string, quoted bool) (interface{}, error) {
+	switch kind {
+	case domain.FilterText:
+		return raw, nil
+	case domain.FilterTime:
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected an RFC 3339 time for the value")
+		}
+		return t, nil
+	}
+	if quoted {
+		return nil, fmt.Errorf("unexpected quoted value")
+	}
+	switch kind {
+	case domain.FilterInteger:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer for the value")
+		}
+		return n, nil
+	case domain.FilterBoolean:
+		if raw != "true" && raw != "false" {
+			return nil, fmt.Errorf("expected true or false for the value")
+		}
+		return raw == "true", nil
+	case domain.FilterDifficulty:
+		difficulty := domain.Difficulty(raw)
+		if difficulty.Rank() == 0 {
+			return nil, fmt.Errorf("expected easy, medium or hard for the value")
+		}
+		return difficulty, nil
+	}
+	return nil, fmt.Errorf("unsupported kind %s for the value", kind)
+}
+
+func filterableFields() []string {
+	fields := make([]string, 0, len(domain.FilterableQuestionFields))
+	for field := range domain.FilterableQuestionFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func joinOperators(operators []domain.FilterOperator) string {
+	names := make([]string, 0, len(operators))
+	for _, operator := range operators {
+		names = append(names, string(operator))
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	tests := []struct {
+		name          string
+		expression    string
+		expected      []domain.Condition
+		expectedError string
+	}{
+		{
+			name:       "conditions",
+			expression: `body~"sun" & options_count>=3&has_multiple_correct=true`,
+			expected: []domain.Condition{
+				{Field: "body", Operator: domain.FilterContains, Value: "sun"},
+				{Field: "options_count", Operator: domain.FilterGreaterOrEqual, Value: 3},
+				{Field: "has_multiple_correct", Operator: domain.FilterEqual, Value: true},
+			},
+		},
+		{
+			name:       "escapes and bare words",
+			expression: `body="say \"hi\" \\ bye" & tag!=go & difficulty<=medium`,
+			expected: []domain.Condition{
+				{Field: "body", Operator: domain.FilterEqual, Value: `say "hi" \ bye`},
+				{Field: "tag", Operator: domain.FilterNotEqual, Value: "go"},
+				{Field: "difficulty", Operator: domain.FilterLessOrEqual, Value: domain.DifficultyMedium},
+			},
+		},
+		{
+			name:       "time",
+			expression: `updated_at > 2024-01-02T03:04:05Z`,
+			expected: []domain.Condition{
+				{Field: "updated_at", Operator: domain.FilterGreater, Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+			},
+		},
+		{name: "empty", expression: "  ", expectedError: "invalid filter at position 2: expected a field, got the end of the filter"},
+		{name: "unknown field", expression: "points>1&owner=me", expectedError: `invalid filter at position 9: unknown field "owner"`},
+		{name: "missing operator", expression: "points 3", expectedError: "invalid filter at position 7: expected an operator after points"},
+		{name: "operator not applying", expression: "points~3", expectedError: "invalid filter at position 6: operator ~ does not apply to points, expected one of = != < <= > >="},
+		{name: "not an integer", expression: "points>three", expectedError: "invalid filter at position 7: expected an integer for the value of points"},
+		{name: "quoted integer", expression: `points>"3"`, expectedError: "invalid filter at position 7: unexpected quoted value of points"},
+		{name: "not a boolean", expression: "has_multiple_correct=yes", expectedError: "expected true or false for the value of has_multiple_correct"},
+		{name: "unterminated string", expression: `body~"sun`, expectedError: "invalid filter at position 5: unterminated string"},
+		{name: "invalid escape", expression: `body~"s\un"`, expectedError: `invalid filter at position 7: invalid escape`},
+		{name: "missing value", expression: "points>", expectedError: "invalid filter at position 7: expected a value, got the end of the filter"},
+		{name: "missing separator", expression: `body~"sun" points>1`, expectedError: "invalid filter at position 11: expected & between conditions"},
+		{name: "trailing separator", expression: "points>1&", expectedError: "invalid filter at position 9: expected a field, got the end of the filter"},
+		{name: "too many conditions", expression: strings.Repeat("points>1&", MAX_FILTER_CONDITIONS) + "points>1", expectedError: "filter has more than 20 conditions"},
+		{name: "too long", expression: `body="` + strings.Repeat("a", MAX_FILTER_LENGTH) + `"`, expectedError: "filter is longer than 1000 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFilterExpression(tt.expression)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("error returned, %v, did not contain %q", err, tt.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("conditions returned, %+v, did not match %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+// FuzzParseFilterExpression checks the parser never panics, points errors inside the filter and only lets
+// through conditions the repository can turn into SQL.
+func FuzzParseFilterExpression(f *testing.F) {
+	for _, seed := range []string{
+		`body~"sun"&options_count>=3&has_multiple_correct=true`,
+		`body="say \"hi\""&tag!=go&difficulty<=medium`,
+		`updated_at>2024-01-02T03:04:05Z`,
+		`points>"3"`,
+		`body~"s\un"`,
+		`&&`,
+		"points\x00>1",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, expression string) {
+		conditions, err := parseFilterExpression(expression)
+		if err != nil {
+			var parseErr filterError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("error %v is not a filter error", err)
+			}
+			if parseErr.pos < 0 || parseErr.pos > len(expression) {
+				t.Fatalf("error position %d is outside of the filter of %d bytes", parseErr.pos, len(expression))
+			}
+			return
+		}
+		if len(conditions) == 0 || len(conditions) > MAX_FILTER_CONDITIONS {
+			t.Fatalf("parsed %d conditions", len(conditions))
+		}
+		for _, condition := range conditions {
+			kind, ok := domain.FilterableQuestionFields[condition.Field]
+			if !ok {
+				t.Fatalf("field %q is not filterable", condition.Field)
+			}
+			if !kind.Allows(condition.Operator) {
+				t.Fatalf("operator %s does not apply to %s", condition.Operator, condition.Field)
+			}
+			var valid bool
+			switch condition.Value.(type) {
+			case int:
+				valid = kind == domain.FilterInteger
+			case string:
+				valid = kind == domain.FilterText
+			case bool:
+				valid = kind == domain.FilterBoolean
+			case time.Time:
+				valid = kind == domain.FilterTime
+			case domain.Difficulty:
+				valid = kind == domain.FilterDifficulty
+			}
+			if !valid {
+				t.Fatalf("value %#v does not fit the %s field %s", condition.Value, kind, condition.Field)
+			}
+		}
+	})
+}
+
+func TestServer_listQuestionsFilter(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	questions := []domain.Question{
+		{ID: 1, Body: "Where does the Sun rise?", Tags: []string{"astronomy"}, Difficulty: domain.DifficultyEasy, Options: []domain.Option{
+			{Body: "East", Correct: true}, {Body: "West"},
+		}},
+		{ID: 2, Body: "Which are planets?", Tags: []string{"astronomy"}, Difficulty: domain.DifficultyMedium, Options: []domain.Option{
+			{Body: "Mars", Correct: true}, {Body: "Venus", Correct: true}, {Body: "The Sun"},
+		}},
+		{ID: 3, Body: "Which keeps the sun_shine 100% on?", Difficulty: domain.DifficultyHard, Options: []domain.Option{
+			{Body: "Fusion", Correct: true}, {Body: "Fire"}, {Body: "Coal"},
+		}},
+	}
+	for _, question := range questions {
+		req := httptest.NewRequest(http.MethodPost, "/questions", buildBufJson(question, t))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		srv.handleQuestions(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+	}
+	// question 1 is written last
+	req := httptest.NewRequest(http.MethodPut, "/questions", buildBufJson(questions[0], t))
+	req.Header.Set("Content-Type", "application/json")
+	srv.handleQuestions(httptest.NewRecorder(), req)
+
+	tests := []struct {
+		name           string
+		filter         string
+		sort           string
+		rawQuery       string
+		expectedIDs    []int
+		expectedStatus int
+	}{
+		{"contains ignoring case", `body~"sun"`, "", "", []int{3, 1}, http.StatusOK},
+		{"like wildcards match themselves", `body~"sun_shine 100%"`, "", "", []int{3}, http.StatusOK},
+		{"options count", `options_count>=3`, "", "", []int{3, 2}, http.StatusOK},
+		{"multiple correct", `has_multiple_correct=true`, "", "", []int{2}, http.StatusOK},
+		{"single correct", `has_multiple_correct=false`, "", "", []int{3, 1}, http.StatusOK},
+		{"combined", `options_count>=3 & difficulty>easy & tag!=astronomy`, "", "", []int{3}, http.StatusOK},
+		{"tag contains", `tag~astro`, "id", "", []int{1, 2}, http.StatusOK},
+		{"sorted by last write", "", "-updated_at,id", "", []int{1, 3, 2}, http.StatusOK},
+		{"recently written", `updated_at>2000-01-01T00:00:00Z&correct_count=1`, "id", "", []int{1, 3}, http.StatusOK},
+		{"malformed", `body~"sun`, "", "", nil, http.StatusBadRequest},
+		{"unknown field", `author=me`, "", "", nil, http.StatusBadRequest},
+		{"unescaped &", "", "", `filter=body~"sun"&options_count>=3`, []int{3}, http.StatusOK},
+		{"unescaped & as requested", "", "", `filter=body~"sun"&options_count>=3&has_multiple_correct=true&sort=-updated_at,id`, []int{}, http.StatusOK},
+		{"unescaped & with a match", "", "", `filter=body~"sun"&options_count>=3&has_multiple_correct=false&sort=-updated_at,id`, []int{3}, http.StatusOK},
+		{"bare conditions", "", "", `tag!=astronomy&points<=10&body~sun`, []int{3}, http.StatusOK},
+		{"bare condition without a value", "", "", `has_multiple_correct=`, nil, http.StatusBadRequest},
+		{"unknown param", "", "", `filter=body~"sun"&author=me`, nil, http.StatusBadRequest},
+		{"escaped &", "", "", `filter=body~"sun"%26options_count>=3`, []int{3}, http.StatusOK},
+		{"repeated filter params", "", "", `filter=body~"sun"&filter=options_count>=3`, []int{3}, http.StatusOK},
+		{"too many conditions across filter params", "", "", strings.Repeat("filter=points>1&", MAX_FILTER_CONDITIONS) + "filter=points>1", nil, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := url.Values{}
+			if tt.filter != "" {
+				values.Set("filter", tt.filter)
+			}
+			if tt.sort != "" {
+				values.Set("sort", tt.sort)
+			}
+			query := values.Encode()
+			if tt.rawQuery != "" {
+				query = tt.rawQuery
+			}
+			rr := httptest.NewRecorder()
+			srv.handleQuestions(rr, httptest.NewRequest(http.MethodGet, "/questions?"+query, nil))
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var listed []domain.Question
+			if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int, 0, len(listed))
+			for _, question := range listed {
+				ids = append(ids, question.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("questions returned, %v, did not match %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+
+	// questions not written since updated_at was recorded have none and sort as the oldest
+	if err := db.Exec(`UPDATE question SET updated_at = NULL where id = 2`).Error; err != nil {
+		t.Fatal(err)
+	}
+	sorts := []struct {
+		sort        string
+		expectedIDs []int
+	}{
+		{"updated_at,id", []int{2, 3, 1}},
+		{"-updated_at,id", []int{1, 3, 2}},
+	}
+	for _, tt := range sorts {
+		rr := httptest.NewRecorder()
+		srv.handleQuestions(rr, httptest.NewRequest(http.MethodGet, "/questions?sort="+tt.sort, nil))
+		var listed []domain.Question
+		if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]int, 0, len(listed))
+		for _, question := range listed {
+			ids = append(ids, question.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.expectedIDs) {
+			t.Errorf("questions sorted by %s, %v, did not match %v", tt.sort, ids, tt.expectedIDs)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	CheckOK      = "ok"
+	CheckFailing = "failing"
+
+	StatusReady    = "ready"
+	StatusNotReady = "not ready"
+	StatusDraining = "draining"
+)
+
+// Check tells if a dependency works, Run returns nil when it does.
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// CheckResult is the outcome of a check, Error says why it is failing.
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+type Health struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+// Readiness tells load balancers whether to send requests, it is ready while every check passes. Once draining
+// it is not ready whatever the checks say, so traffic moves elsewhere before the server shuts down.
+type Readiness struct {
+	checks     []Check
+	timeout    time.Duration
+	drainDelay time.Duration
+	draining   atomic.Bool
+}
+
+// NewReadiness gives every check timeout to pass. Draining lasts drainDelay before the server shuts down, about
+// as long as load balancers take to see it is not ready.
+func NewReadiness(timeout, drainDelay time.Duration, checks ...Check) *Readiness {
+	return &Readiness{checks: checks, timeout: timeout, drainDelay: drainDelay}
+}
+
+// Check runs every check at once.
+func (r *Readiness) Check(ctx context.Context) Health {
+	if r.draining.Load() {
+		return Health{Status: StatusDraining}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	results := make([]CheckResult, len(r.checks))
+	var wg sync.WaitGroup
+	for i, check := range r.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			start := time.Now()
+			result := CheckResult{Name: check.Name, Status: CheckOK}
+			if err := check.Run(ctx); err != nil {
+				result.Status, result.Error = CheckFailing, err.Error()
+			}
+			result.DurationMS = time.Since(start).Milliseconds()
+			results[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	health := Health{Status: StatusReady, Checks: results}
+	for _, result := range results {
+		if result.Status != CheckOK {
+			health.Status = StatusNotReady
+		}
+	}
+	return health
+}
+
+// drain stops the readiness from being ready and waits for load balancers to notice.
+func (r *Readiness) drain() {
+	if r == nil {
+		return
+	}
+	r.draining.Store(true)
+	slog.Info("draining before shutdown", "delay", r.drainDelay)
+	time.Sleep(r.drainDelay)
+}
+
+// handleLivez answers as long as the process serves requests, whatever its dependencies, so it is only
+// restarted when it is stuck.
+func (s Server) handleLivez(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	writeHealth(w, r, http.StatusOK, Health{Status: CheckOK})
+}
+
+// handleReadyz answers 503 unless every check passes, with the outcome of each. Without a readiness the server
+// is always ready.
+func (s Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+	health := Health{Status: StatusReady}
+	if s.readiness != nil {
+		health = s.readiness.Check(r.Context())
+	}
+
+	status := http.StatusOK
+	if health.Status != StatusReady {
+		status = http.StatusServiceUnavailable
+		slog.WarnContext(r.Context(), "not ready", "status", health.Status, "checks", health.Checks)
+	}
+	writeHealth(w, r, status, health)
+}
+
+func writeHealth(w http.ResponseWriter, r *http.Request, status int, health Health) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(health); err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response health", "err", err)
+	}
+}
@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_handleLivez(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name           string
+		r              *http.Request
+		expectedStatus int
+	}{
+		{name: "alive", r: httptest.NewRequest(http.MethodGet, "/livez", nil), expectedStatus: http.StatusOK},
+		{name: "invalid method", r: httptest.NewRequest(http.MethodPost, "/livez", nil), expectedStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.handleLivez(rr, tt.r)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Code, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestServer_handleReadyz(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	passing := Check{Name: "database", Run: func(ctx context.Context) error { return nil }}
+	failing := Check{Name: "disk", Run: func(ctx context.Context) error { return errors.New("read-only file system") }}
+	slow := Check{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+	draining := NewReadiness(time.Second, 0, passing)
+	draining.drain()
+
+	tests := []struct {
+		name           string
+		readiness      *Readiness
+		expectedStatus int
+		expectedHealth Health
+	}{
+		{name: "no readiness", expectedStatus: http.StatusOK, expectedHealth: Health{Status: StatusReady}},
+		{name: "every check passes", readiness: NewReadiness(time.Second, 0, passing),
+			expectedStatus: http.StatusOK,
+			expectedHealth: Health{Status: StatusReady, Checks: []CheckResult{{Name: "database", Status: CheckOK}}}},
+		{name: "a check fails", readiness: NewReadiness(time.Second, 0, passing, failing),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{Status: StatusNotReady, Checks: []CheckResult{
+				{Name: "database", Status: CheckOK},
+				{Name: "disk", Status: CheckFailing, Error: "read-only file system"},
+			}}},
+		{name: "a check times out", readiness: NewReadiness(time.Millisecond, 0, slow),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{Status: StatusNotReady, Checks: []CheckResult{
+				{Name: "slow", Status: CheckFailing, Error: context.DeadlineExceeded.Error()},
+			}}},
+		{name: "draining", readiness: draining, expectedStatus: http.StatusServiceUnavailable,
+			expectedHealth: Health{Status: StatusDraining}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := *srv
+			s.readiness = tt.readiness
+			rr := httptest.NewRecorder()
+			s.handleReadyz(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Code, tt.expectedStatus)
+			}
+
+			var health Health
+			if err := json.Unmarshal(rr.Body.Bytes(), &health); err != nil {
+				t.Fatal(err)
+			}
+			for i := range health.Checks {
+				health.Checks[i].DurationMS = 0
+			}
+			if !reflect.DeepEqual(health, tt.expectedHealth) {
+				t.Errorf("health returned, %+v, did not match expected %+v", health, tt.expectedHealth)
+			}
+		})
+	}
+}
+
+func TestServer_closeDrains(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	srv.readiness = NewReadiness(time.Second, 0)
+
+	if health := srv.readiness.Check(context.Background()); health.Status != StatusReady {
+		t.Fatalf("readiness before close is %s, expected %s", health.Status, StatusReady)
+	}
+	if err := srv.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if health := srv.readiness.Check(context.Background()); health.Status != StatusDraining {
+		t.Errorf("readiness after close is %s, expected %s", health.Status, StatusDraining)
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// messageLocales are the languages field errors are explained in, the first one is the fallback.
+var messageLocales = []language.Tag{language.English, language.Spanish, language.German, language.French}
+
+var messageMatcher = language.NewMatcher(messageLocales)
+
+// messages holds the explanation of every rule by language. Size rules are looked up with the kind of the
+// field appended, "max.characters", {param} is replaced by the argument of the rule.
+var messages = map[language.Tag]map[string]string{
+	language.English: {
+		"required":           "is required",
+		"min.characters":     "must be at least {param} characters long",
+		"min.items":          "must have at least {param} items",
+		"min.value":          "must be at least {param}",
+		"max.characters":     "must be at most {param} characters long",
+		"max.items":          "must have at most {param} items",
+		"max.value":          "must be at most {param}",
+		"oneof":              "must be one of {param}",
+		"bcp47_language_tag": "must be a BCP 47 language tag",
+		"one_correct":        "must have at least one correct option",
+		"single_answer":      "must have only one correct option",
+		"distinct":           "must differ from the other options",
+		"banned_word":        "must not contain the word {param}",
+		"invalid":            "is not valid",
+	},
+	language.Spanish: {
+		"required":           "es obligatorio",
+		"min.characters":     "debe tener al menos {param} caracteres",
+		"min.items":          "debe tener al menos {param} elementos",
+		"min.value":          "debe ser como mínimo {param}",
+		"max.characters":     "debe tener como máximo {param} caracteres",
+		"max.items":          "debe tener como máximo {param} elementos",
+		"max.value":          "debe ser como máximo {param}",
+		"oneof":              "debe ser uno de {param}",
+		"bcp47_language_tag": "debe ser una etiqueta de idioma BCP 47",
+		"one_correct":        "debe tener al menos una opción correcta",
+		"single_answer":      "debe tener una sola opción correcta",
+		"distinct":           "debe ser distinta de las demás opciones",
+		"banned_word":        "no debe contener la palabra {param}",
+		"invalid":            "no es válido",
+	},
+	language.German: {
+		"required":           "ist erforderlich",
+		"min.characters":     "muss mindestens {param} Zeichen lang sein",
+		"min.items":          "muss mindestens {param} Einträge haben",
+		"min.value":          "muss mindestens {param} sein",
+		"max.characters":     "darf höchstens {param} Zeichen lang sein",
+		"max.items":          "darf höchstens {param} Einträge haben",
+		"max.value":          "darf höchstens {param} sein",
+		"oneof":              "muss einer von {param} sein",
+		"bcp47_language_tag": "muss ein BCP-47-Sprachtag sein",
+		"one_correct":        "muss mindestens eine richtige Antwort haben",
+		"single_answer":      "darf nur eine richtige Antwort haben",
+		"distinct":           "muss sich von den anderen Antworten unterscheiden",
+		"banned_word":        "darf das Wort {param} nicht enthalten",
+		"invalid":            "ist ungültig",
+	},
+	language.French: {
+		"required":           "est obligatoire",
+		"min.characters":     "doit contenir au moins {param} caractères",
+		"min.items":          "doit contenir au moins {param} éléments",
+		"min.value":          "doit être au moins {param}",
+		"max.characters":     "doit contenir au plus {param} caractères",
+		"max.items":          "doit contenir au plus {param} éléments",
+		"max.value":          "doit être au plus {param}",
+		"oneof":              "doit être l'une des valeurs {param}",
+		"bcp47_language_tag": "doit être une étiquette de langue BCP 47",
+		"one_correct":        "doit avoir au moins une option correcte",
+		"single_answer":      "doit avoir une seule option correcte",
+		"distinct":           "doit être différente des autres options",
+		"banned_word":        "ne doit pas contenir le mot {param}",
+		"invalid":            "n'est pas valide",
+	},
+}
+
+// sizeRules are counted like min and max, gte and lte only differ from them on numbers and times.
+var sizeRules = map[string]string{"min": "min", "max": "max", "gte": "min", "lte": "max"}
+
+// problemLocale picks the language of the messages from an Accept-Language header.
+func problemLocale(header string) language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return messageLocales[0]
+	}
+	_, index, _ := messageMatcher.Match(tags...)
+	return messageLocales[index]
+}
+
+// fieldMessage explains a field error, rules without a message of their own are just not valid.
+func fieldMessage(locale language.Tag, fe FieldError) string {
+	catalog := messages[locale]
+	key := fe.Code
+	if rule, ok := sizeRules[fe.Code]; ok {
+		key = rule + "." + fe.kind
+	}
+	message, ok := catalog[key]
+	if !ok {
+		message = catalog["invalid"]
+	}
+	return strings.ReplaceAll(message, "{param}", fe.Param)
+}
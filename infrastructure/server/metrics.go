@@ -0,0 +1,87 @@
+package server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const METRICS_PATH = "/metrics"
+
+// Metrics counts and times the requests of every route. Routes are the patterns handlers are registered on,
+// so ids in paths do not make up new series.
+type Metrics struct {
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+	inFlight prometheus.Gauge
+	gatherer prometheus.Gatherer
+	token    string
+}
+
+// NewMetrics registers the HTTP metrics on registry, /metrics serves everything registered on it to scrapers
+// sending token as a bearer token.
+func NewMetrics(registry *prometheus.Registry, token string) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "HTTP requests answered, by route, method and status.",
+		}, []string{"route", "method", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Time taken to answer HTTP requests, by route, method and status.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "HTTP requests being answered.",
+		}),
+		gatherer: registry,
+		token:    token,
+	}
+	for _, collector := range []prometheus.Collector{m.requests, m.duration, m.inFlight} {
+		if err := registry.Register(collector); err != nil {
+			return nil, fmt.Errorf("err registering http metrics:%w", err)
+		}
+	}
+	return m, nil
+}
+
+// Handler serves the metrics in the Prometheus text format. They span every organization and are outside of
+// the roles of any, so only the scraper holding the token reads them, no one does without a token.
+func (m *Metrics) Handler() http.Handler {
+	metrics := promhttp.HandlerFor(m.gatherer, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if m.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(m.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeProblem(w, r, http.StatusUnauthorized, errUnauthenticated.Error())
+			return
+		}
+		metrics.ServeHTTP(w, r)
+	})
+}
+
+// instrument records the requests handler answers as requests of route. Without metrics it is handler.
+func (m *Metrics) instrument(route string, handler http.HandlerFunc) http.HandlerFunc {
+	if m == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w}
+		handler(recorder, r)
+
+		labels := prometheus.Labels{"route": route, "method": r.Method, "status": strconv.Itoa(recorder.status())}
+		m.requests.With(labels).Inc()
+		m.duration.With(labels).Observe(time.Since(start).Seconds())
+	}
+}
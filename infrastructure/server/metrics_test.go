@@ -0,0 +1,103 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestMetrics_instrument(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	metrics, err := NewMetrics(prometheus.NewRegistry(), "scraper")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := []struct {
+		route   string
+		handler http.HandlerFunc
+		r       *http.Request
+	}{
+		{"/livez", srv.handleLivez, httptest.NewRequest(http.MethodGet, "/livez", nil)},
+		{"/livez", srv.handleLivez, httptest.NewRequest(http.MethodGet, "/livez", nil)},
+		{"/livez", srv.handleLivez, httptest.NewRequest(http.MethodPost, "/livez", nil)},
+		{"/questions/", srv.handleQuestion, httptest.NewRequest(http.MethodGet, "/questions/7", nil)},
+		{"/questions/", srv.handleQuestion, httptest.NewRequest(http.MethodGet, "/questions/8", nil)},
+		{"/questions", srv.handleQuestions, httptest.NewRequest(http.MethodGet, "/questions", nil)},
+	}
+	for _, req := range requests {
+		metrics.instrument(req.route, req.handler)(httptest.NewRecorder(), req.r)
+	}
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, METRICS_PATH, nil)
+	r.Header.Set("Authorization", "Bearer scraper")
+	metrics.Handler().ServeHTTP(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Code, http.StatusOK)
+	}
+
+	expected := []string{
+		`http_requests_total{method="GET",route="/livez",status="200"} 2`,
+		`http_requests_total{method="POST",route="/livez",status="405"} 1`,
+		`http_requests_total{method="GET",route="/questions/",status="404"} 2`,
+		`http_requests_total{method="GET",route="/questions",status="200"} 1`,
+		`http_request_duration_seconds_count{method="GET",route="/livez",status="200"} 2`,
+		`http_requests_in_flight 0`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(rr.Body.String(), line) {
+			t.Errorf("metrics do not have %s:\n%s", line, rr.Body.String())
+		}
+	}
+}
+
+func TestMetrics_handlerToken(t *testing.T) {
+	tests := []struct {
+		name           string
+		token          string
+		authorization  string
+		expectedStatus int
+	}{
+		{name: "scraper with the token", token: "scraper", authorization: "Bearer scraper", expectedStatus: http.StatusOK},
+		{name: "anonymous", token: "scraper", expectedStatus: http.StatusUnauthorized},
+		{name: "wrong token", token: "scraper", authorization: "Bearer guess", expectedStatus: http.StatusUnauthorized},
+		{name: "no token configured", authorization: "Bearer ", expectedStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics, err := NewMetrics(prometheus.NewRegistry(), tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := httptest.NewRequest(http.MethodGet, METRICS_PATH, nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			metrics.Handler().ServeHTTP(rr, r)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Code, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK && strings.Contains(rr.Body.String(), "http_requests_in_flight") {
+				t.Errorf("metrics were served without the token:\n%s", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestMetrics_nil(t *testing.T) {
+	var metrics *Metrics
+	called := false
+	handler := metrics.instrument("/livez", func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if !called {
+		t.Errorf("handler was not called without metrics")
+	}
+}
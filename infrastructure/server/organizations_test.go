@@ -0,0 +1,283 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/usecase"
+)
+
+// organizationRequest acts as an admin of organization, anonymously on the default one when it is empty.
+func organizationRequest(t *testing.T, method, path, organization string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, path, body)
+	if body != nil {
+		r.Header.Add("Content-Type", "application/json")
+	}
+	if organization != "" {
+		token, err := NewAuthenticator(TEST_TOKEN_SECRET, "", usecase.APIKeys{}).Issue(Claims{Subject: "admin", Organization: organization, Roles: []Role{RoleAdmin}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Add("Authorization", "Bearer "+token)
+		r.Header.Add(ORGANIZATION_HEADER, organization)
+	}
+	return r
+}
+
+func TestServer_organizationIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+	questions := srv.guard(questionPermission, Server.handleQuestions)
+	question := srv.guard(questionPermission, Server.handleQuestion)
+
+	acme := domain.Question{ID: 1, Body: "acme only", Tags: []string{"go"}, Options: []domain.Option{
+		{Body: "a", Correct: true}, {Body: "b"},
+	}}
+	rr := httptest.NewRecorder()
+	questions(rr, organizationRequest(t, http.MethodPost, "/questions", "acme", buildBufJson(acme, t)))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	rr = httptest.NewRecorder()
+	question(rr, organizationRequest(t, http.MethodPost, "/questions/1/responses", "acme", bytes.NewBufferString(`{"selected":[0]}`)))
+	if rr.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusCreated)
+	}
+
+	for _, organization := range []string{"globex", ""} {
+		rr := httptest.NewRecorder()
+		questions(rr, organizationRequest(t, http.MethodGet, "/questions", organization, nil))
+		var listed []domain.Question
+		if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+			t.Fatal(err)
+		}
+		if len(listed) != 0 {
+			t.Errorf("organization %q listed questions of acme, %+v", organization, listed)
+		}
+	}
+
+	updated := acme
+	updated.Body = "taken over"
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		path    string
+		body    string
+	}{
+		{name: "get", handler: question, method: http.MethodGet, path: "/questions/1"},
+		{name: "update", handler: questions, method: http.MethodPut, path: "/questions", body: buildBufJson(updated, t).String()},
+		{name: "delete", handler: question, method: http.MethodDelete, path: "/questions/1"},
+		{name: "translate", handler: question, method: http.MethodPut, path: "/questions/1/translations/es", body: `{"body":"solo acme","options":[{"body":"a","correct":true},{"body":"b"}]}`},
+		{name: "candidate view", handler: question, method: http.MethodGet, path: "/questions/1/candidate"},
+		{name: "respond", handler: question, method: http.MethodPost, path: "/questions/1/responses", body: `{"selected":[0]}`},
+		{name: "stats", handler: question, method: http.MethodGet, path: "/questions/1/stats"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+" from another organization should throw 404", func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = bytes.NewBufferString(tt.body)
+			}
+			rr := httptest.NewRecorder()
+			tt.handler(rr, organizationRequest(t, tt.method, tt.path, "globex", body))
+			if rr.Result().StatusCode != http.StatusNotFound {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusNotFound)
+			}
+		})
+	}
+
+	rr = httptest.NewRecorder()
+	question(rr, organizationRequest(t, http.MethodGet, "/questions/1", "acme", nil))
+	var got domain.Question
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, acme) {
+		t.Errorf("question of acme, %+v, was changed from another organization, expected %+v", got, acme)
+	}
+
+	globex := repo.ForOrganization("globex")
+	responses, err := globex.GetResponses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := globex.GetItemCounts(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scores, err := globex.GetItemScores(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(responses) != 0 || len(counts) != 0 || len(scores) != 0 {
+		t.Errorf("repository of globex saw acme data, responses %+v, counts %+v, scores %+v", responses, counts, scores)
+	}
+	if err := globex.SaveItemParameters([]domain.ItemParameters{{QuestionID: 1, Discrimination: 1}}); err != domain.ErrNoQuestionFound {
+		t.Errorf("saving parameters of a question of acme returned %v, expected %v", err, domain.ErrNoQuestionFound)
+	}
+	organizations, err := repo.GetOrganizations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(organizations, []string{"acme"}) {
+		t.Errorf("organizations returned, %v, did not match expected [acme]", organizations)
+	}
+}
+
+func TestServer_organizationQuestionIDs(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	questions := srv.guard(questionPermission, Server.handleQuestions)
+	question := srv.guard(questionPermission, Server.handleQuestion)
+
+	tests := []struct {
+		name           string
+		organization   string
+		body           string
+		expectedStatus int
+	}{
+		{name: "first organization should add the id", organization: "acme", body: "acme one", expectedStatus: http.StatusOK},
+		{name: "another organization should add the same id", organization: "globex", body: "globex one", expectedStatus: http.StatusOK},
+		{name: "same organization adding the id again should throw 409", organization: "acme", body: "acme again", expectedStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := domain.Question{ID: 1, Body: tt.body, Options: []domain.Option{{Body: "a", Correct: true}, {Body: "b"}}}
+			rr := httptest.NewRecorder()
+			questions(rr, organizationRequest(t, http.MethodPost, "/questions", tt.organization, buildBufJson(q, t)))
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+
+	for organization, expected := range map[string]string{"acme": "acme one", "globex": "globex one"} {
+		rr := httptest.NewRecorder()
+		question(rr, organizationRequest(t, http.MethodGet, "/questions/1", organization, nil))
+		var got domain.Question
+		if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Body != expected {
+			t.Errorf("question 1 of %s, %q, did not match expected %q", organization, got.Body, expected)
+		}
+	}
+}
+
+func TestServer_organizationAttachments(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	body, contentType := buildMultipart(buildPNG(t), t)
+	rr := httptest.NewRecorder()
+	r := organizationRequest(t, http.MethodPost, "/attachments", "acme", body)
+	r.Header.Set("Content-Type", contentType)
+	srv.guard(always(PermissionCreate), Server.handleAttachments)(rr, r)
+	if rr.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusCreated)
+	}
+	var attachment domain.Attachment
+	if err := json.Unmarshal(rr.Body.Bytes(), &attachment); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		organization   string
+		expectedStatus int
+	}{
+		{name: "owner should get the attachment", organization: "acme", expectedStatus: http.StatusOK},
+		{name: "another organization should throw 404", organization: "globex", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.guard(readOrWrite(PermissionCreate), Server.handleAttachment)(rr, organizationRequest(t, http.MethodGet, "/attachments/"+attachment.ID, tt.organization, nil))
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+
+	question := domain.Question{ID: 1, Body: "steal", Attachments: []string{attachment.ID}, Options: []domain.Option{{Body: "a", Correct: true}}}
+	rr = httptest.NewRecorder()
+	srv.guard(questionPermission, Server.handleQuestions)(rr, organizationRequest(t, http.MethodPost, "/questions", "globex", buildBufJson(question, t)))
+	if rr.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Status code returned, %d, did not match expected code %d for an attachment of acme", rr.Result().StatusCode, http.StatusBadRequest)
+	}
+}
+
+func TestServer_tenantResolution(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	_ = repo.ForOrganization("acme").Add(context.Background(), domain.Question{ID: 1, Body: "acme", Options: []domain.Option{{Body: "a", Correct: true}}})
+
+	issue := func(auth Authenticator, claims Claims) string {
+		token, err := auth.Issue(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+	auth := NewAuthenticator(TEST_TOKEN_SECRET, "", srv.auth.keys)
+	acmeToken := issue(auth, Claims{Subject: "ann", Organization: "acme", Roles: []Role{RoleViewer}})
+
+	tests := []struct {
+		name           string
+		authorization  string
+		organization   string
+		expectedStatus int
+		expectedCount  int
+	}{
+		{name: "token should select its organization", authorization: acmeToken, expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "header matching the token should be OK", authorization: acmeToken, organization: "acme", expectedStatus: http.StatusOK, expectedCount: 1},
+		{name: "header naming another organization than the token should throw 403", authorization: acmeToken, organization: "globex", expectedStatus: http.StatusForbidden},
+		{name: "token signed with another secret should throw 401", authorization: issue(NewAuthenticator("other", "", srv.auth.keys), Claims{Organization: "acme"}), expectedStatus: http.StatusUnauthorized},
+		{name: "expired token should throw 401", authorization: issue(auth, Claims{Organization: "acme", ExpiresAt: time.Now().Add(-time.Minute).Unix()}), expectedStatus: http.StatusUnauthorized},
+		{name: "token without organization should throw 401", authorization: issue(auth, Claims{Subject: "ann"}), expectedStatus: http.StatusUnauthorized},
+		{name: "non bearer authorization should throw 401", authorization: "Basic YW5uOnB3", expectedStatus: http.StatusUnauthorized},
+		{name: "header alone naming another organization should throw 401", organization: "acme", expectedStatus: http.StatusUnauthorized},
+		{name: "header alone naming the default organization should be OK", organization: domain.DefaultOrganization, expectedStatus: http.StatusOK, expectedCount: 0},
+		{name: "invalid organization should throw 400", organization: "Acme Corp", expectedStatus: http.StatusBadRequest},
+		{name: "no organization should use the default one", expectedStatus: http.StatusOK, expectedCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/questions", nil)
+			if tt.organization != "" {
+				r.Header.Add(ORGANIZATION_HEADER, tt.organization)
+			}
+			if tt.authorization != "" {
+				r.Header.Add("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			srv.guard(questionPermission, Server.handleQuestions)(rr, r)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+			var listed []domain.Question
+			if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+				t.Fatal(err)
+			}
+			if len(listed) != tt.expectedCount {
+				t.Errorf("questions listed, %d, did not match expected %d", len(listed), tt.expectedCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/togglhire/backend-homework/domain"
+)
+
+const (
+	PROBLEM_CONTENT_TYPE = "application/problem+json"
+	// VALIDATION_PROBLEM_TYPE identifies problems listing the fields of the request that are not valid.
+	VALIDATION_PROBLEM_TYPE = "/problems/validation"
+	// RETRY_UNAVAILABLE_AFTER is the seconds clients are asked to wait when the database is unavailable.
+	RETRY_UNAVAILABLE_AFTER = "5"
+)
+
+// Problem is an RFC 7807 problem details document, every error of the api is answered with one.
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is one rule a field of the request body breaks. Field is the json path of the field, Code
+// the name of the rule and Param its argument, Message explains it in the language of the caller.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+	// kind tells how size rules count, see kindOf
+	kind string
+}
+
+// fieldErrors is an error carrying the field errors of a request body, messages are filled in when written.
+type fieldErrors []FieldError
+
+func (e fieldErrors) Error() string {
+	rules := make([]string, 0, len(e))
+	for _, fe := range e {
+		rules = append(rules, fe.Field+": "+fe.Code)
+	}
+	return "invalid fields, " + strings.Join(rules, ", ")
+}
+
+// validate is shared by the handlers, it caches the rules of every struct it has seen. Fields are named
+// after their json names so errors point at what the caller sent.
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	return v
+}
+
+// validationErrors turns what the validator returns and rule violations into field errors, other errors
+// are returned as is.
+func validationErrors(err error) error {
+	var violations domain.Violations
+	if errors.As(err, &violations) {
+		fields := make(fieldErrors, 0, len(violations))
+		for _, violation := range violations {
+			fields = append(fields, FieldError{Field: violation.Field, Code: violation.Code, Param: violation.Param})
+		}
+		return fields
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return err
+	}
+	fields := make(fieldErrors, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, FieldError{Field: fieldPath(fe.Namespace()), Code: fe.Tag(), Param: fe.Param(), kind: kindOf(fe.Kind())})
+	}
+	return fields
+}
+
+// fieldPath drops the name of the validated struct, "Question.options[1].body" becomes "options[1].body".
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// kindOf tells how a size rule is counted, characters of strings, items of lists or the value of numbers.
+func kindOf(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+	return "value"
+}
+
+// writeProblem answers with a problem of the status, the title is the text of the status.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblemDocument(w, r, Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail})
+}
+
+// writeError answers err with the status of its kind, handlers only deal with the errors they answer
+// differently. Running out of time answers 504 and a canceled request 503, errors of no known kind are logged
+// with detail and answer 500.
+func writeError(w http.ResponseWriter, r *http.Request, detail string, err error) {
+	switch {
+	case errors.As(err, new(*http.MaxBytesError)):
+		writeTooLarge(w, r, err)
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.ErrorContext(r.Context(), detail, "err", err)
+		writeProblem(w, r, http.StatusGatewayTimeout, "the database did not answer in time")
+	case errors.Is(err, context.Canceled):
+		writeProblem(w, r, http.StatusServiceUnavailable, "the request was canceled")
+	case errors.Is(err, domain.ErrUnavailable):
+		slog.ErrorContext(r.Context(), detail, "err", err)
+		w.Header().Set("Retry-After", RETRY_UNAVAILABLE_AFTER)
+		writeProblem(w, r, http.StatusServiceUnavailable, "the database is unavailable, try again later")
+	case errors.Is(err, domain.ErrNotFound):
+		writeProblem(w, r, http.StatusNotFound, "")
+	case errors.Is(err, domain.ErrConflict):
+		writeProblem(w, r, http.StatusConflict, domainMessage(err))
+	case errors.As(err, new(domain.Violations)):
+		writeInvalid(w, r, err)
+	case errors.Is(err, domain.ErrInvalid):
+		writeProblem(w, r, http.StatusBadRequest, domainMessage(err))
+	default:
+		slog.ErrorContext(r.Context(), detail, "err", err)
+		writeProblem(w, r, http.StatusInternalServerError, detail)
+	}
+}
+
+// writeBodyError answers 413 when the body is larger than allowed, otherwise 400 with detail.
+func writeBodyError(w http.ResponseWriter, r *http.Request, detail string, err error) {
+	if errors.As(err, new(*http.MaxBytesError)) {
+		writeTooLarge(w, r, err)
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, detail)
+}
+
+func writeTooLarge(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	errors.As(err, &tooLarge)
+	writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("the request body is larger than %d bytes", tooLarge.Limit))
+}
+
+// domainMessage is the message of the domain error in the chain of err, without the context the layers
+// above added.
+func domainMessage(err error) string {
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Error()
+	}
+	return err.Error()
+}
+
+// writeInvalid answers 400 with the field errors of err, errors that are not about fields become the detail.
+func writeInvalid(w http.ResponseWriter, r *http.Request, err error) {
+	var fields fieldErrors
+	if !errors.As(validationErrors(err), &fields) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	locale := problemLocale(r.Header.Get("Accept-Language"))
+	problem := Problem{
+		Type:   VALIDATION_PROBLEM_TYPE,
+		Title:  "Your request is not valid",
+		Status: http.StatusBadRequest,
+		Errors: make([]FieldError, 0, len(fields)),
+	}
+	for _, fe := range fields {
+		fe.Message = fieldMessage(locale, fe)
+		problem.Errors = append(problem.Errors, fe)
+	}
+	w.Header().Set("Content-Language", locale.String())
+	w.Header().Add("Vary", "Accept-Language")
+	writeProblemDocument(w, r, problem)
+}
+
+func writeProblemDocument(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Instance = r.URL.Path
+	w.Header().Set("Content-Type", PROBLEM_CONTENT_TYPE)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(problem.Status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		slog.ErrorContext(r.Context(), "err encoding problem", "err", err)
+	}
+}
@@ -0,0 +1,249 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_validationProblem(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name             string
+		question         domain.Question
+		acceptLanguage   string
+		expectedErrors   []FieldError
+		expectedLanguage string
+	}{
+		{
+			name: "option body too long",
+			question: domain.Question{ID: 1, Body: "Where does the sun set?", Options: []domain.Option{
+				{Body: "East", Correct: false},
+				{Body: strings.Repeat("a", 256), Correct: true},
+			}},
+			expectedErrors: []FieldError{
+				{Field: "options[1].body", Code: "max", Param: "255", Message: "must be at most 255 characters long"},
+			},
+			expectedLanguage: "en",
+		},
+		{
+			name:           "every violation at once in spanish",
+			question:       domain.Question{ID: 1, Body: "", Options: []domain.Option{{Body: "East", Correct: false}}},
+			acceptLanguage: "es-ES,es;q=0.9,en;q=0.5",
+			expectedErrors: []FieldError{
+				{Field: "body", Code: "required", Message: "es obligatorio"},
+				{Field: "options", Code: "min", Param: "2", Message: "debe tener al menos 2 elementos"},
+				{Field: "options", Code: "one_correct", Message: "debe tener al menos una opción correcta"},
+			},
+			expectedLanguage: "es",
+		},
+		{
+			name: "unsupported language falls back to english",
+			question: domain.Question{ID: 1, Body: "Where does the sun set?", Points: 2000, Options: []domain.Option{
+				{Body: "East", Correct: false},
+				{Body: "West", Correct: true},
+			}},
+			acceptLanguage: "ja",
+			expectedErrors: []FieldError{
+				{Field: "points", Code: "lte", Param: "1000", Message: "must be at most 1000"},
+			},
+			expectedLanguage: "en",
+		},
+		{
+			name: "german",
+			question: domain.Question{ID: 1, Body: "Where does the sun set?", Difficulty: "impossible", Options: []domain.Option{
+				{Body: "East", Correct: false},
+				{Body: "West", Correct: true},
+			}},
+			acceptLanguage: "de-CH",
+			expectedErrors: []FieldError{
+				{Field: "difficulty", Code: "oneof", Param: "easy medium hard", Message: "muss einer von easy medium hard sein"},
+			},
+			expectedLanguage: "de",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/questions", buildBufJson(tt.question, t))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			srv.handleQuestions(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+			}
+			if got := rr.Header().Get("Content-Type"); got != PROBLEM_CONTENT_TYPE {
+				t.Errorf("content type returned, %s, did not match %s", got, PROBLEM_CONTENT_TYPE)
+			}
+			if got := rr.Header().Get("Content-Language"); got != tt.expectedLanguage {
+				t.Errorf("content language returned, %s, did not match %s", got, tt.expectedLanguage)
+			}
+
+			var problem Problem
+			if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Type != VALIDATION_PROBLEM_TYPE || problem.Status != http.StatusBadRequest || problem.Instance != "/questions" {
+				t.Errorf("unexpected problem %+v", problem)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.expectedErrors) {
+				t.Errorf("errors returned, %+v, did not match %+v", problem.Errors, tt.expectedErrors)
+			}
+		})
+	}
+}
+
+func TestServer_problem(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedDetail string
+	}{
+		{"unknown question", http.MethodGet, "/questions/42", srv.handleQuestion, http.StatusNotFound, ""},
+		{"invalid method", http.MethodPatch, "/questions", srv.handleQuestions, http.StatusMethodNotAllowed, "invalid http method"},
+		{"invalid filter", http.MethodGet, "/questions?difficulty=impossible", srv.handleQuestions, http.StatusBadRequest, `invalid difficulty "impossible", expected easy, medium or hard`},
+		{"unauthenticated", http.MethodGet, "/questions", buildGuardedServer(srv), http.StatusUnauthorized, errUnauthenticated.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler(rr, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Code, tt.expectedStatus)
+			}
+			if got := rr.Header().Get("Content-Type"); got != PROBLEM_CONTENT_TYPE {
+				t.Errorf("content type returned, %s, did not match %s", got, PROBLEM_CONTENT_TYPE)
+			}
+			var problem Problem
+			if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if problem.Type != "about:blank" || problem.Status != tt.expectedStatus || problem.Title != http.StatusText(tt.expectedStatus) {
+				t.Errorf("unexpected problem %+v", problem)
+			}
+			if tt.expectedDetail != "" && problem.Detail != tt.expectedDetail {
+				t.Errorf("detail returned, %q, did not match %q", problem.Detail, tt.expectedDetail)
+			}
+		})
+	}
+}
+
+// buildGuardedServer guards the question routes of a server that lets no one in anonymously.
+func buildGuardedServer(srv *Server) http.HandlerFunc {
+	closed := *srv
+	closed.auth = NewAuthenticator(TEST_TOKEN_SECRET, "", closed.auth.keys)
+	return closed.guard(questionPermission, Server.handleQuestions)
+}
+
+// failingQuestions fails every call with err.
+type failingQuestions struct {
+	err error
+}
+
+func (f failingQuestions) GetAll(context.Context) ([]domain.Question, error) {
+	return nil, f.err
+}
+
+func (f failingQuestions) Find(context.Context, domain.QuestionFilter) ([]domain.Question, error) {
+	return nil, f.err
+}
+
+func (f failingQuestions) Get(context.Context, int) (domain.Question, error) {
+	return domain.Question{}, f.err
+}
+
+func (f failingQuestions) Add(context.Context, domain.Question) error {
+	return f.err
+}
+
+func (f failingQuestions) Update(context.Context, domain.Question) (domain.Question, error) {
+	return domain.Question{}, f.err
+}
+
+func (f failingQuestions) Delete(context.Context, int) error {
+	return f.err
+}
+
+func TestServer_domainErrors(t *testing.T) {
+	db := setupTestDB(t)
+	question := domain.Question{ID: 1, Body: "hello", Options: []domain.Option{
+		{Body: "option a"}, {Body: "option b", Correct: true},
+	}}
+
+	errs := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"not found", domain.ErrNoQuestionFound, http.StatusNotFound},
+		{"conflict", domain.ErrQuestionExists, http.StatusConflict},
+		{"invalid", domain.ErrInvalidSelection, http.StatusBadRequest},
+		{"unavailable", domain.WrapError(domain.ErrUnavailable, errors.New("database is locked")), http.StatusServiceUnavailable},
+		{"deadline", fmt.Errorf("err getting question:%w", context.DeadlineExceeded), http.StatusGatewayTimeout},
+		{"unknown", errors.New("disk on fire"), http.StatusInternalServerError},
+	}
+	jsonRequest := func(method string, question domain.Question) *http.Request {
+		r := httptest.NewRequest(method, "/questions", buildBufJson(question, t))
+		r.Header.Add("Content-Type", "application/json")
+		return r
+	}
+	requests := []struct {
+		name    string
+		handler func(Server) http.HandlerFunc
+		request func() *http.Request
+	}{
+		{"list", func(s Server) http.HandlerFunc { return s.handleQuestions },
+			func() *http.Request { return httptest.NewRequest(http.MethodGet, "/questions", nil) }},
+		{"add", func(s Server) http.HandlerFunc { return s.handleQuestions },
+			func() *http.Request { return jsonRequest(http.MethodPost, question) }},
+		{"update", func(s Server) http.HandlerFunc { return s.handleQuestions },
+			func() *http.Request { return jsonRequest(http.MethodPut, question) }},
+		{"get", func(s Server) http.HandlerFunc { return s.handleQuestion },
+			func() *http.Request { return httptest.NewRequest(http.MethodGet, "/questions/1", nil) }},
+		{"delete", func(s Server) http.HandlerFunc { return s.handleQuestion },
+			func() *http.Request { return httptest.NewRequest(http.MethodDelete, "/questions/1", nil) }},
+		{"stats", func(s Server) http.HandlerFunc { return s.handleQuestion },
+			func() *http.Request { return httptest.NewRequest(http.MethodGet, "/questions/1/stats", nil) }},
+		{"candidate view", func(s Server) http.HandlerFunc { return s.handleQuestion },
+			func() *http.Request { return httptest.NewRequest(http.MethodGet, "/questions/1/candidate", nil) }},
+	}
+	for _, e := range errs {
+		srv := buildServerWithQuestions(sql.NewRepo(db), failingQuestions{err: e.err}, t)
+		for _, req := range requests {
+			t.Run(e.name+" on "+req.name, func(t *testing.T) {
+				rr := httptest.NewRecorder()
+				req.handler(*srv)(rr, req.request())
+				if rr.Code != e.expectedStatus {
+					t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, e.expectedStatus, rr.Body.String())
+				}
+				if got := rr.Header().Get("Content-Type"); got != "application/problem+json" {
+					t.Errorf("Content-Type returned, %q, is not a problem", got)
+				}
+				if e.expectedStatus == http.StatusServiceUnavailable && rr.Header().Get("Retry-After") == "" {
+					t.Errorf("unavailable answered without Retry-After")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// questionFilterParams are the query params parseQuestionFilter reads.
+var questionFilterParams = map[string]bool{
+	"tags": true, "difficulty": true, "min_points": true, "max_points": true,
+	"min_estimated_seconds": true, "max_estimated_seconds": true, "filter": true, "sort": true,
+}
+
+// parseQuestionFilter reads the list filters from the query string, for example
+// ?tags=go,sql&difficulty=easy,medium&min_points=5&max_estimated_seconds=120&sort=-points,estimated_seconds
+// and conditions in the filter params, see parseFilterExpression. Repeated filter params are all applied.
+// Conditions after an unescaped & in a filter arrive as params of their own, as options_count>=3 in
+// ?filter=body~"sun"&options_count>=3, those starting with a filterable field are applied too and any other
+// param is rejected.
+func parseQuestionFilter(query url.Values) (domain.QuestionFilter, error) {
+	var filter domain.QuestionFilter
+
+	var bare []string
+	for param := range query {
+		if !questionFilterParams[param] {
+			bare = append(bare, param)
+		}
+	}
+	sort.Strings(bare)
+	for _, param := range bare {
+		if _, ok := domain.FilterableQuestionFields[strings.TrimRight(param[:filterFieldEnd(param)], " ")]; !ok {
+			return domain.QuestionFilter{}, fmt.Errorf("unknown query param %q", param)
+		}
+		for _, value := range query[param] {
+			conditions, err := parseFilterExpression(bareCondition(param, value))
+			if err != nil {
+				return domain.QuestionFilter{}, err
+			}
+			filter.Conditions = append(filter.Conditions, conditions...)
+		}
+	}
+
+	filter.Tags = splitList(query.Get("tags"))
+
+	for _, value := range splitList(query.Get("difficulty")) {
+		difficulty := domain.Difficulty(value)
+		if difficulty.Rank() == 0 {
+			return domain.QuestionFilter{}, fmt.Errorf("invalid difficulty %q, expected easy, medium or hard", value)
+		}
+		filter.Difficulties = append(filter.Difficulties, difficulty)
+	}
+
+	bounds := []struct {
+		param string
+		dest  **int
+	}{
+		{"min_points", &filter.MinPoints},
+		{"max_points", &filter.MaxPoints},
+		{"min_estimated_seconds", &filter.MinEstimatedSeconds},
+		{"max_estimated_seconds", &filter.MaxEstimatedSeconds},
+	}
+	for _, bound := range bounds {
+		value := query.Get(bound.param)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return domain.QuestionFilter{}, fmt.Errorf("invalid %s %q, expected an integer", bound.param, value)
+		}
+		*bound.dest = &n
+	}
+
+	for _, value := range query["filter"] {
+		if value == "" {
+			continue
+		}
+		conditions, err := parseFilterExpression(value)
+		if err != nil {
+			return domain.QuestionFilter{}, err
+		}
+		filter.Conditions = append(filter.Conditions, conditions...)
+	}
+	if len(filter.Conditions) > MAX_FILTER_CONDITIONS {
+		return domain.QuestionFilter{}, fmt.Errorf("filter has more than %d conditions", MAX_FILTER_CONDITIONS)
+	}
+
+	for _, value := range splitList(query.Get("sort")) {
+		field := domain.SortField{Field: strings.TrimPrefix(value, "-"), Desc: strings.HasPrefix(value, "-")}
+		if !domain.IsSortableQuestionField(field.Field) {
+			return domain.QuestionFilter{}, fmt.Errorf("invalid sort field %q, expected one of %s", field.Field, strings.Join(domain.SortableQuestionFields, ", "))
+		}
+		filter.Sort = append(filter.Sort, field)
+	}
+
+	return filter, nil
+}
+
+func parseIDs(value string) ([]int, error) {
+	values := splitList(value)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing question ids")
+	}
+	ids := make([]int, 0, len(values))
+	for _, v := range values {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid question id %q", v)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// filterFieldEnd is where the field of a condition ends, at the first operator or the end.
+func filterFieldEnd(condition string) int {
+	if i := strings.IndexAny(condition, "=!<>~"); i >= 0 {
+		return i
+	}
+	return len(condition)
+}
+
+// bareCondition puts back the = the query string split a condition on, options_count>=3 is read as the param
+// options_count> valued 3 and body~"sun" as the param body~"sun" with no value.
+func bareCondition(param string, value string) string {
+	if value == "" && filterFieldEnd(param) < len(param) {
+		return param
+	}
+	return param + "=" + value
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// parseAuditFilter reads the audit filters from the query string, for example
+// ?question_id=3&actor=ann&action=update&from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z&limit=20&cursor=120
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{Actor: query.Get("actor")}
+
+	if value := query.Get("question_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return domain.AuditFilter{}, fmt.Errorf("invalid question_id %q, expected an integer", value)
+		}
+		filter.QuestionID = &id
+	}
+
+	if value := query.Get("action"); value != "" {
+		action, ok := domain.ParseAuditAction(value)
+		if !ok {
+			return domain.AuditFilter{}, fmt.Errorf("invalid action %q, expected create, update, delete, save_translation or delete_translation", value)
+		}
+		filter.Action = action
+	}
+
+	times := []struct {
+		param string
+		dest  **time.Time
+	}{
+		{"from", &filter.From},
+		{"to", &filter.To},
+	}
+	for _, t := range times {
+		value := query.Get(t.param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return domain.AuditFilter{}, fmt.Errorf("invalid %s %q, expected an RFC 3339 time", t.param, value)
+		}
+		*t.dest = &parsed
+	}
+
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return domain.AuditFilter{}, fmt.Errorf("invalid limit %q, expected a positive integer", value)
+		}
+		filter.Limit = n
+	}
+	if value := query.Get("cursor"); value != "" {
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 {
+			return domain.AuditFilter{}, fmt.Errorf("invalid cursor %q", value)
+		}
+		filter.Cursor = n
+	}
+
+	return filter, nil
+}
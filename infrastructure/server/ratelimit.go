@@ -0,0 +1,133 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// idle buckets are dropped at most this often, a full bucket holds nothing worth keeping
+const RATE_LIMIT_SWEEP_INTERVAL = time.Minute
+
+// RateLimit refills Rate tokens per second up to Burst, a zero rate means no limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// Decision is the outcome of taking a token, Reset is how long until the bucket is full again.
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	limit   RateLimit
+	tokens  float64
+	updated time.Time
+}
+
+func (b bucket) full(now time.Time) bool {
+	return b.tokens+now.Sub(b.updated).Seconds()*b.limit.Rate >= float64(b.limit.Burst)
+}
+
+// RateLimiter keeps one token bucket per client and kind of request, reads and writes are limited apart.
+type RateLimiter struct {
+	mu        sync.Mutex
+	reads     RateLimit
+	writes    RateLimit
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewRateLimiter(reads, writes RateLimit, now func() time.Time) *RateLimiter {
+	return &RateLimiter{reads: reads, writes: writes, buckets: make(map[string]*bucket), now: now, lastSweep: now()}
+}
+
+// Allow takes a token from the bucket of the client.
+func (l *RateLimiter) Allow(client string, write bool) Decision {
+	limit, kind := l.reads, "read:"
+	if write {
+		limit, kind = l.writes, "write:"
+	}
+	if limit.Rate <= 0 || limit.Burst <= 0 {
+		return Decision{Allowed: true}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[kind+client]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), updated: now}
+		l.buckets[kind+client] = b
+	}
+	b.tokens = math.Min(float64(limit.Burst), b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	b.updated = now
+
+	decision := Decision{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return decision
+}
+
+// sweep forgets the buckets that had time to fill up again, they would start full anyway.
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < RATE_LIMIT_SWEEP_INTERVAL {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// writeRateLimitHeaders follows the RateLimit header fields draft, times are whole seconds rounded up.
+func writeRateLimitHeaders(w http.ResponseWriter, decision Decision) {
+	if decision.Limit == 0 {
+		return
+	}
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(decision.Reset)))
+	if !decision.Allowed {
+		w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(decision.RetryAfter)))
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// rateLimitKey is the api key, else the user, else the address of the client.
+func rateLimitKey(identity Identity, r *http.Request) string {
+	if identity.Subject != "" {
+		return identity.Organization + "/" + identity.Subject
+	}
+	return "ip:" + clientIP(r)
+}
+
+func isWrite(r *http.Request) bool {
+	return r.Method != http.MethodGet && r.Method != http.MethodHead && r.Method != http.MethodOptions
+}
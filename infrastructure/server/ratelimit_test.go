@@ -0,0 +1,119 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+// fakeClock only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestRateLimiter_Allow(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(RateLimit{Rate: 1, Burst: 3}, RateLimit{Rate: 0.5, Burst: 1}, clock.Now)
+
+	steps := []struct {
+		name       string
+		advance    time.Duration
+		client     string
+		write      bool
+		allowed    bool
+		remaining  int
+		retryAfter time.Duration
+	}{
+		{name: "first read takes from a full bucket", client: "ann", allowed: true, remaining: 2},
+		{name: "second read", client: "ann", allowed: true, remaining: 1},
+		{name: "third read empties the bucket", client: "ann", allowed: true, remaining: 0},
+		{name: "fourth read is refused", client: "ann", allowed: false, remaining: 0, retryAfter: time.Second},
+		{name: "other client has its own bucket", client: "bob", allowed: true, remaining: 2},
+		{name: "writes have their own bucket", client: "ann", write: true, allowed: true, remaining: 0},
+		{name: "second write is refused", client: "ann", write: true, allowed: false, retryAfter: 2 * time.Second},
+		{name: "half a second refills half a token", advance: 500 * time.Millisecond, client: "ann", allowed: false, retryAfter: 500 * time.Millisecond},
+		{name: "a second later a read passes", advance: 500 * time.Millisecond, client: "ann", allowed: true, remaining: 0},
+		{name: "two seconds refill a write", advance: 2 * time.Second, client: "ann", write: true, allowed: true, remaining: 0},
+		{name: "refill stops at the burst", advance: time.Hour, client: "ann", allowed: true, remaining: 2},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		decision := limiter.Allow(step.client, step.write)
+		if decision.Allowed != step.allowed || decision.Remaining != step.remaining || decision.RetryAfter != step.retryAfter {
+			t.Errorf("%s: decision returned, %+v, did not match expected allowed %t remaining %d retry after %s",
+				step.name, decision, step.allowed, step.remaining, step.retryAfter)
+		}
+	}
+}
+
+func TestRateLimiter_sweep(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(RateLimit{Rate: 1, Burst: 2}, RateLimit{}, clock.Now)
+
+	limiter.Allow("ann", false)
+	clock.Advance(RATE_LIMIT_SWEEP_INTERVAL)
+	limiter.Allow("bob", false)
+	if _, ok := limiter.buckets["read:ann"]; ok {
+		t.Errorf("bucket of an idle client should be forgotten once full")
+	}
+	if decision := limiter.Allow("ann", true); !decision.Allowed || decision.Limit != 0 {
+		t.Errorf("decision returned, %+v, expected writes not to be limited without a rate", decision)
+	}
+}
+
+func TestServer_rateLimit(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	srv.limiter = NewRateLimiter(RateLimit{Rate: 1, Burst: 2}, RateLimit{Rate: 0.1, Burst: 1}, clock.Now)
+	handler := srv.guard(questionPermission, Server.handleQuestions)
+
+	send := func(method, remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/questions", nil)
+		r.RemoteAddr = remoteAddr
+		rr := httptest.NewRecorder()
+		handler(rr, r)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := send(http.MethodGet, "10.0.0.1:1234"); rr.Result().StatusCode != http.StatusOK {
+			t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+		}
+	}
+
+	rr := send(http.MethodGet, "10.0.0.1:5678")
+	if rr.Result().StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusTooManyRequests)
+	}
+	expected := map[string]string{"Retry-After": "1", "RateLimit-Limit": "2", "RateLimit-Remaining": "0", "RateLimit-Reset": "2"}
+	for header, value := range expected {
+		if got := rr.Header().Get(header); got != value {
+			t.Errorf("header %s returned, %q, did not match expected %q", header, got, value)
+		}
+	}
+
+	if rr := send(http.MethodGet, "10.0.0.2:1234"); rr.Result().StatusCode != http.StatusOK {
+		t.Errorf("Status code returned, %d, did not match expected code %d for another address", rr.Result().StatusCode, http.StatusOK)
+	}
+
+	if rr := send(http.MethodPut, "10.0.0.1:1234"); rr.Result().StatusCode == http.StatusTooManyRequests {
+		t.Errorf("writes should not be limited by the reads")
+	}
+	rr = send(http.MethodPut, "10.0.0.1:1234")
+	if rr.Result().StatusCode != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "10" {
+		t.Errorf("second write returned %d with Retry-After %q, expected %d after 10 seconds", rr.Result().StatusCode, rr.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	clock.Advance(time.Second)
+	if rr := send(http.MethodGet, "10.0.0.1:1234"); rr.Result().StatusCode != http.StatusOK {
+		t.Errorf("Status code returned, %d, did not match expected code %d after the refill", rr.Result().StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,117 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleAuthor   Role = "author"
+	RoleReviewer Role = "reviewer"
+	RoleViewer   Role = "viewer"
+	// RoleCandidate only answers questions and reads its own results.
+	RoleCandidate Role = "candidate"
+)
+
+type Permission string
+
+const (
+	PermissionList    Permission = "list"
+	PermissionCreate  Permission = "create"
+	PermissionUpdate  Permission = "update"
+	PermissionDelete  Permission = "delete"
+	PermissionPublish Permission = "publish"
+	PermissionExport  Permission = "export"
+	PermissionAudit   Permission = "audit"
+	// PermissionRespond answers questions and reads the results as the caller, it writes responses so read-only
+	// roles lack it.
+	PermissionRespond Permission = "respond"
+	// PermissionConfigure changes the settings of the organization, such as its validation rules.
+	PermissionConfigure Permission = "configure"
+	// PermissionManageKeys is never granted to api keys, a leaked key can not mint others.
+	PermissionManageKeys Permission = "manage_keys"
+)
+
+// policy is everything a role may do, a role missing from the table may do nothing.
+var policy = map[Role][]Permission{
+	RoleAdmin:     {PermissionList, PermissionCreate, PermissionUpdate, PermissionDelete, PermissionPublish, PermissionExport, PermissionAudit, PermissionRespond, PermissionConfigure, PermissionManageKeys},
+	RoleAuthor:    {PermissionList, PermissionCreate, PermissionUpdate},
+	RoleReviewer:  {PermissionList, PermissionPublish, PermissionExport, PermissionAudit},
+	RoleViewer:    {PermissionList},
+	RoleCandidate: {PermissionRespond},
+}
+
+// Allowed tells whether any of the roles grants the permission.
+func Allowed(roles []Role, permission Permission) bool {
+	for _, role := range roles {
+		for _, granted := range policy[role] {
+			if granted == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// forbidden explains a refused request, roles or scopes are listed so the caller knows what it was seen as.
+func forbidden(identity Identity, permission Permission) string {
+	if identity.APIKey {
+		names := make([]string, 0, len(identity.Scopes))
+		for _, scope := range identity.Scopes {
+			names = append(names, string(scope))
+		}
+		return fmt.Sprintf("forbidden: api key scopes [%s] do not grant %s", strings.Join(names, ", "), permission)
+	}
+	names := make([]string, 0, len(identity.Roles))
+	for _, role := range identity.Roles {
+		names = append(names, string(role))
+	}
+	return fmt.Sprintf("forbidden: roles [%s] do not grant %s", strings.Join(names, ", "), permission)
+}
+
+// requires maps a request to the permission it needs.
+type requires func(r *http.Request) Permission
+
+func always(permission Permission) requires {
+	return func(*http.Request) Permission { return permission }
+}
+
+// readOrWrite needs list to read and the write permission for anything else.
+func readOrWrite(write Permission) requires {
+	return func(r *http.Request) Permission {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			return PermissionList
+		}
+		return write
+	}
+}
+
+// questionPermission covers /questions and everything below it. Summaries only read the library, candidate
+// responses and views need respond and translations change a question.
+func questionPermission(r *http.Request) Permission {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if strings.HasSuffix(r.URL.Path, "/candidate") {
+			return PermissionRespond
+		}
+		return PermissionList
+	case http.MethodPut:
+		return PermissionUpdate
+	case http.MethodDelete:
+		if strings.Contains(r.URL.Path, "/translations/") {
+			return PermissionUpdate
+		}
+		return PermissionDelete
+	}
+	if r.URL.Path == "/questions/summary" {
+		return PermissionList
+	}
+	if strings.HasSuffix(r.URL.Path, "/responses") {
+		return PermissionRespond
+	}
+	return PermissionCreate
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestAllowed(t *testing.T) {
+	all := []Permission{PermissionList, PermissionCreate, PermissionUpdate, PermissionDelete, PermissionPublish, PermissionExport,
+		PermissionAudit, PermissionRespond, PermissionManageKeys}
+	tests := []struct {
+		role    Role
+		allowed []Permission
+	}{
+		{role: RoleAdmin, allowed: all},
+		{role: RoleAuthor, allowed: []Permission{PermissionList, PermissionCreate, PermissionUpdate}},
+		{role: RoleReviewer, allowed: []Permission{PermissionList, PermissionPublish, PermissionExport, PermissionAudit}},
+		{role: RoleViewer, allowed: []Permission{PermissionList}},
+		{role: RoleCandidate, allowed: []Permission{PermissionRespond}},
+		{role: Role("intern")},
+	}
+	for _, tt := range tests {
+		for _, permission := range all {
+			t.Run(string(tt.role)+" "+string(permission), func(t *testing.T) {
+				expected := false
+				for _, p := range tt.allowed {
+					expected = expected || p == permission
+				}
+				if got := Allowed([]Role{tt.role}, permission); got != expected {
+					t.Errorf("Allowed returned, %t, did not match expected %t", got, expected)
+				}
+			})
+		}
+	}
+
+	if !Allowed([]Role{RoleViewer, RoleAuthor}, PermissionCreate) {
+		t.Errorf("any of the roles should grant the permission")
+	}
+	if Allowed(nil, PermissionList) {
+		t.Errorf("no role should grant nothing")
+	}
+}
+
+func TestQuestionPermission(t *testing.T) {
+	tests := []struct {
+		method   string
+		path     string
+		expected Permission
+	}{
+		{method: http.MethodGet, path: "/questions", expected: PermissionList},
+		{method: http.MethodPost, path: "/questions", expected: PermissionCreate},
+		{method: http.MethodPut, path: "/questions", expected: PermissionUpdate},
+		{method: http.MethodDelete, path: "/questions/1", expected: PermissionDelete},
+		{method: http.MethodPost, path: "/questions/summary", expected: PermissionList},
+		{method: http.MethodPost, path: "/questions/1/responses", expected: PermissionRespond},
+		{method: http.MethodGet, path: "/questions/1/responses", expected: PermissionList},
+		{method: http.MethodPut, path: "/questions/1/translations/es", expected: PermissionUpdate},
+		{method: http.MethodDelete, path: "/questions/1/translations/es", expected: PermissionUpdate},
+		{method: http.MethodGet, path: "/questions/1/stats", expected: PermissionList},
+		{method: http.MethodGet, path: "/questions/1/candidate", expected: PermissionRespond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			if got := questionPermission(httptest.NewRequest(tt.method, tt.path, nil)); got != tt.expected {
+				t.Errorf("permission returned, %s, did not match expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestServer_guard(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+	srv.auth = NewAuthenticator(TEST_TOKEN_SECRET, "", srv.auth.keys)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "guarded", Options: []domain.Option{{Body: "a", Correct: true}}})
+
+	token := func(roles ...Role) string {
+		token, err := srv.auth.Issue(Claims{Subject: "ann", Organization: domain.DefaultOrganization, Roles: roles})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+
+	questions := srv.guard(questionPermission, Server.handleQuestion)
+	sessions := srv.guard(always(PermissionRespond), Server.handleAdaptiveSession)
+
+	tests := []struct {
+		name           string
+		authorization  string
+		handler        http.HandlerFunc
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedReason string
+	}{
+		{name: "anonymous should throw 401", method: http.MethodGet, path: "/questions/1", expectedStatus: http.StatusUnauthorized},
+		{name: "viewer should list", authorization: token(RoleViewer), method: http.MethodGet, path: "/questions/1", expectedStatus: http.StatusOK},
+		{name: "viewer should not delete", authorization: token(RoleViewer), method: http.MethodDelete, path: "/questions/1", expectedStatus: http.StatusForbidden, expectedReason: "roles [viewer] do not grant delete"},
+		{name: "token without roles should not list", authorization: token(), method: http.MethodGet, path: "/questions/1", expectedStatus: http.StatusForbidden, expectedReason: "roles [] do not grant list"},
+		{name: "author should not delete", authorization: token(RoleAuthor), method: http.MethodDelete, path: "/questions/1", expectedStatus: http.StatusForbidden, expectedReason: "roles [author] do not grant delete"},
+		{name: "viewer should not respond", authorization: token(RoleViewer), method: http.MethodPost, path: "/questions/1/responses", body: `{"candidate":"ann","selected":[0]}`, expectedStatus: http.StatusForbidden, expectedReason: "roles [viewer] do not grant respond"},
+		{name: "candidate should not respond as another", authorization: token(RoleCandidate), method: http.MethodPost, path: "/questions/1/responses", body: `{"candidate":"bob","selected":[0]}`, expectedStatus: http.StatusForbidden, expectedReason: "the candidate must be the caller"},
+		{name: "candidate should respond", authorization: token(RoleCandidate), method: http.MethodPost, path: "/questions/1/responses", body: `{"candidate":"ann","selected":[0]}`, expectedStatus: http.StatusCreated},
+		{name: "candidate should read its result", authorization: token(RoleCandidate), method: http.MethodGet, path: "/questions/1/candidate", expectedStatus: http.StatusOK, expectedReason: `"result"`},
+		{name: "candidate should not read the result of another", authorization: token(RoleCandidate), method: http.MethodGet, path: "/questions/1/candidate?candidate=bob", expectedStatus: http.StatusForbidden, expectedReason: "the candidate must be the caller"},
+		{name: "viewer should not read candidate views", authorization: token(RoleViewer), method: http.MethodGet, path: "/questions/1/candidate", expectedStatus: http.StatusForbidden, expectedReason: "roles [viewer] do not grant respond"},
+		{name: "candidate should read adaptive sessions", authorization: token(RoleCandidate), handler: sessions, method: http.MethodGet, path: "/adaptive-sessions/missing", expectedStatus: http.StatusNotFound},
+		{name: "viewer should not read adaptive sessions", authorization: token(RoleViewer), handler: sessions, method: http.MethodGet, path: "/adaptive-sessions/missing", expectedStatus: http.StatusForbidden, expectedReason: "roles [viewer] do not grant respond"},
+		{name: "candidate should not list", authorization: token(RoleCandidate), method: http.MethodGet, path: "/questions/1", expectedStatus: http.StatusForbidden, expectedReason: "roles [candidate] do not grant list"},
+		{name: "admin should delete", authorization: token(RoleAdmin), method: http.MethodDelete, path: "/questions/1", expectedStatus: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			r := httptest.NewRequest(tt.method, tt.path, body)
+			r.Header.Set("Content-Type", "application/json")
+			if tt.authorization != "" {
+				r.Header.Add("Authorization", tt.authorization)
+			}
+			handler := tt.handler
+			if handler == nil {
+				handler = questions
+			}
+			rr := httptest.NewRecorder()
+			handler(rr, r)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedReason) {
+				t.Errorf("reason returned, %q, did not contain %q", rr.Body.String(), tt.expectedReason)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// recovered answers 500 when handler panics, unless it already started answering, and logs the panic with the
+// stack where it happened. http.ErrAbortHandler is panicked again, it aborts the response on purpose.
+func recovered(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			slog.ErrorContext(r.Context(), "panic answering request", "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+			if recorder.code == 0 {
+				writeProblem(recorder, r, http.StatusInternalServerError, "Internal error")
+			}
+		}()
+		handler(recorder, r)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRecovered(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedStatus int
+		expectedBody   string
+		panics         bool
+	}{
+		{"panic answers 500", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}, http.StatusInternalServerError, "", true},
+		{"panic after answering keeps the answer", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("partial"))
+			panic("boom")
+		}, http.StatusAccepted, "partial", true},
+		{"no panic", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte("fine"))
+		}, http.StatusOK, "fine", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			rr := httptest.NewRecorder()
+			recovered(tt.handler)(rr, httptest.NewRequest(http.MethodGet, "/questions", nil))
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Code, tt.expectedStatus)
+			}
+			if tt.expectedBody != "" {
+				if rr.Body.String() != tt.expectedBody {
+					t.Errorf("body returned, %q, did not match expected %q", rr.Body.String(), tt.expectedBody)
+				}
+			} else {
+				var problem Problem
+				if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil || problem.Status != tt.expectedStatus {
+					t.Errorf("body returned, %s, is not a problem of status %d", rr.Body.String(), tt.expectedStatus)
+				}
+			}
+			panicked := strings.Contains(logs.String(), "panic answering request")
+			if panicked != tt.panics {
+				t.Errorf("panic logged is %t, expected %t:\n%s", panicked, tt.panics, logs.String())
+			}
+		})
+	}
+}
+
+func TestRecovered_abortHandler(t *testing.T) {
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("panic recovered, %v, did not match expected %v", p, http.ErrAbortHandler)
+		}
+	}()
+	recovered(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/questions", nil))
+}
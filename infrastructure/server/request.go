@@ -0,0 +1,101 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"regexp"
+
+	"github.com/togglhire/backend-homework/infrastructure/logging"
+)
+
+const (
+	REQUEST_ID_HEADER = "X-Request-Id"
+	ANONYMOUS_SUBJECT = "anonymous"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// withRequestID gives every request an id, see requestID, that the records logged while answering it carry.
+func withRequestID(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(w, r)
+		handler(w, r.WithContext(logging.WithRequestID(r.Context(), id)))
+	}
+}
+
+// requestID is the id withRequestID gave the request. Otherwise it keeps the id sent by the client or a proxy
+// when it looks sane, else makes one up, and echoes it back so both sides can refer to the request.
+func requestID(w http.ResponseWriter, r *http.Request) string {
+	if id := logging.RequestID(r.Context()); id != "" {
+		return id
+	}
+	id := r.Header.Get(REQUEST_ID_HEADER)
+	if !requestIDPattern.MatchString(id) {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err == nil {
+			id = hex.EncodeToString(b)
+		} else {
+			id = ""
+		}
+	}
+	if id != "" {
+		w.Header().Set(REQUEST_ID_HEADER, id)
+	}
+	return id
+}
+
+// limitBody cuts the body of requests off after limit bytes, reading past it fails with an *http.MaxBytesError
+// and closes the connection once answered. A zero limit does not cut them off.
+func limitBody(limit int64, handler http.HandlerFunc) http.HandlerFunc {
+	if limit <= 0 {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		handler(w, r)
+	}
+}
+
+// clientIP is the address of the peer, forwarded headers are not trusted.
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// statusRecorder remembers the status a handler answered with, 200 when it wrote without setting one, and
+// how many bytes of body it wrote.
+type statusRecorder struct {
+	http.ResponseWriter
+	code    int
+	written int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.written += n
+	return n, err
+}
+
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
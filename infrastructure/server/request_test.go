@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestLimitBody(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	question := domain.Question{ID: 1, Body: "which limit applies?", Options: []domain.Option{{Body: "this one", Correct: true}, {Body: "that one"}}}
+	large := question
+	large.Body = strings.Repeat("long ", 300)
+	jsonRequest := func(question domain.Question) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/questions", buildBufJson(question, t))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+
+	tests := []struct {
+		name           string
+		limit          int64
+		r              func() *http.Request
+		handler        http.HandlerFunc
+		expectedStatus int
+	}{
+		{"json body within the limit", 1024, func() *http.Request {
+			return jsonRequest(question)
+		}, srv.handleQuestions, http.StatusOK},
+		{"json body over the limit", 1024, func() *http.Request {
+			return jsonRequest(large)
+		}, srv.handleQuestions, http.StatusRequestEntityTooLarge},
+		{"no limit decodes and validates the body", 0, func() *http.Request {
+			return jsonRequest(large)
+		}, srv.handleQuestions, http.StatusBadRequest},
+		{"upload within the limit", 512, func() *http.Request {
+			body, contentType := buildMultipart(buildPNG(t), t)
+			r := httptest.NewRequest(http.MethodPost, "/attachments", body)
+			r.Header.Set("Content-Type", contentType)
+			return r
+		}, srv.handleAttachments, http.StatusCreated},
+		{"upload over the limit", 512, func() *http.Request {
+			body, contentType := buildMultipart(append(buildPNG(t), make([]byte, 800)...), t)
+			r := httptest.NewRequest(http.MethodPost, "/attachments", body)
+			r.Header.Set("Content-Type", contentType)
+			return r
+		}, srv.handleAttachments, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			limitBody(tt.limit, tt.handler)(rr, tt.r())
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d: %s", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+var errOtherCandidate = errors.New("forbidden: the candidate must be the caller")
+
+// candidate is who the request answers or reads results as, always the caller. A candidate named in the body
+// or query must be the caller, else anyone could answer or read results in the name of someone else.
+func (s Server) candidate(named string) (string, int, error) {
+	if named != "" && named != s.identity.Subject {
+		return "", http.StatusForbidden, errOtherCandidate
+	}
+	return s.identity.Subject, http.StatusOK, nil
+}
+
+// handleCandidateView shows the question as candidates see it, with the result of the caller once answered.
+func (s Server) handleCandidateView(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	candidate, status, err := s.candidate(r.URL.Query().Get("candidate"))
+	if err != nil {
+		writeProblem(w, r, status, err.Error())
+		return
+	}
+
+	view, err := s.responses.CandidateView(r.Context(), id, candidate)
+
+	if err != nil {
+		writeError(w, r, "Internal error getting candidate view", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(view)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response candidate view", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) handleResponses(w http.ResponseWriter, r *http.Request, id int) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	var response domain.Response
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&response)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if s.identity.Subject == "" {
+		writeProblem(w, r, http.StatusUnauthorized, errUnauthenticated.Error())
+		return
+	}
+	var status int
+	response.Candidate, status, err = s.candidate(response.Candidate)
+	if err != nil {
+		writeProblem(w, r, status, err.Error())
+		return
+	}
+
+	response.QuestionID = id
+	if err := validate.Struct(response); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+
+	result, err := s.responses.Submit(r.Context(), response)
+
+	if err != nil {
+		writeError(w, r, "Internal error submitting response", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response submit response", "err", err)
+	}
+}
@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func submitResponse(srv *Server, questionID string, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/questions/"+questionID+"/responses", bytes.NewBufferString(body))
+	r.Header.Add("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.handleQuestion(rr, r)
+	return rr
+}
+
+func TestServer_explanationsRevealedAfterSubmit(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+	srv.identity = Identity{Subject: "alice"}
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "Where does the sun set?", Explanation: "The earth spins eastwards.",
+		Options: []domain.Option{
+			{Body: "East", Feedback: "That is where it rises."}, {Body: "West", Correct: true, Feedback: "Right."},
+		}})
+	_ = repo.Add(context.Background(), domain.Question{ID: 2, Body: "Where does the sun rise?", Explanation: "The earth spins eastwards.", Reveal: domain.RevealNever,
+		Options: []domain.Option{
+			{Body: "East", Correct: true, Feedback: "Right."}, {Body: "West"},
+		}})
+
+	rr := httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1", nil))
+	if !strings.Contains(rr.Body.String(), "The earth spins eastwards.") || !strings.Contains(rr.Body.String(), "That is where it rises.") {
+		t.Errorf("author view should include explanation and feedback, got %s", rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/candidate?candidate=alice", nil))
+	if rr.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusOK)
+	}
+	for _, hidden := range []string{"correct", "explanation", "feedback", "result"} {
+		if strings.Contains(rr.Body.String(), hidden) {
+			t.Errorf("candidate view before submitting should not include %s, got %s", hidden, rr.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name           string
+		questionID     string
+		body           string
+		expectedStatus int
+	}{
+		{name: "response without selected options should fail with 400", questionID: "1", body: `{"candidate":"alice"}`, expectedStatus: http.StatusBadRequest},
+		{name: "response as another candidate should fail with 403", questionID: "1", body: `{"candidate":"bob","selected":[1]}`, expectedStatus: http.StatusForbidden},
+		{name: "response with unknown option should fail with 400", questionID: "1", body: `{"candidate":"alice","selected":[2]}`, expectedStatus: http.StatusBadRequest},
+		{name: "response to non existent question should throw 404", questionID: "3", body: `{"candidate":"alice","selected":[1]}`, expectedStatus: http.StatusNotFound},
+		{name: "valid response should be created as the caller", questionID: "1", body: `{"selected":[1]}`, expectedStatus: http.StatusCreated},
+		{name: "second response of the same candidate should fail with 409", questionID: "1", body: `{"candidate":"alice","selected":[0]}`, expectedStatus: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := submitResponse(srv, tt.questionID, tt.body)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/candidate?candidate=alice", nil))
+	var view domain.CandidateQuestion
+	if err := json.Unmarshal(rr.Body.Bytes(), &view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Result == nil || view.Result.Correct == nil || !*view.Result.Correct {
+		t.Fatalf("candidate view after submitting should include a correct result, got %+v", view.Result)
+	}
+	if view.Result.Explanation != "The earth spins eastwards." || view.Result.Options[0].Feedback != "That is where it rises." || !view.Result.Options[1].Selected {
+		t.Errorf("candidate view after submitting should include explanation and feedback, got %+v", view.Result)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/candidate?candidate=bob", nil))
+	if rr.Result().StatusCode != http.StatusForbidden {
+		t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, http.StatusForbidden)
+	}
+
+	rr = submitResponse(srv, "2", `{"candidate":"alice","selected":[1]}`)
+	var result domain.Result
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Correct != nil || result.Explanation != "" || len(result.Options) != 0 {
+		t.Errorf("question that never reveals should not include the answer, got %+v", result)
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// validationRules lists the rules the organization turned on and the ones it could.
+type validationRules struct {
+	Rules     []domain.RuleSetting `json:"rules"`
+	Available []string             `json:"available"`
+}
+
+func (s Server) handleValidationRules(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.getValidationRules(w, r)
+	case http.MethodPut:
+		s.saveValidationRules(w, r)
+	default:
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+	}
+}
+
+func (s Server) getValidationRules(w http.ResponseWriter, r *http.Request) {
+
+	settings, err := s.rules.Settings(r.Context())
+	if err != nil {
+		writeError(w, r, "Internal error getting validation rules", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(validationRules{Rules: settings, Available: s.rules.Available()})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response get validation rules", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) saveValidationRules(w http.ResponseWriter, r *http.Request) {
+
+	var settings []domain.RuleSetting
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&settings)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if err := validate.Var(settings, "max=50,dive"); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+
+	err = s.rules.Save(r.Context(), settings)
+	if errors.Is(err, domain.ErrInvalidRuleSetting) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, r, "Internal error saving validation rules", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(validationRules{Rules: settings, Available: s.rules.Available()})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response save validation rules", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
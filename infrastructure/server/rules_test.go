@@ -0,0 +1,151 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_saveValidationRules(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+
+	tests := []struct {
+		name           string
+		body           string
+		expectedStatus int
+	}{
+		{"unknown rule", `[{"name":"no_typos"}]`, http.StatusBadRequest},
+		{"missing params", `[{"name":"banned_words"}]`, http.StatusBadRequest},
+		{"unexpected params", `[{"name":"single_answer","params":["yes"]}]`, http.StatusBadRequest},
+		{"missing name", `[{"params":["foo"]}]`, http.StatusBadRequest},
+		{"valid", `[{"name":"distinct_options"},{"name":"banned_words","params":["foo"]}]`, http.StatusOK},
+		{"none", `[]`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/validation-rules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			srv.handleValidationRules(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestServer_validationRules(t *testing.T) {
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
+	rules := srv.guard(readOrWrite(PermissionConfigure), Server.handleValidationRules)
+	questions := srv.guard(questionPermission, Server.handleQuestions)
+
+	rr := httptest.NewRecorder()
+	rules(rr, organizationRequest(t, http.MethodPut, "/validation-rules", "acme", bytes.NewBufferString(
+		`[{"name":"distinct_options"},{"name":"single_answer"},{"name":"banned_words","params":["Obviously"]}]`,
+	)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	rules(rr, organizationRequest(t, http.MethodGet, "/validation-rules", "acme", nil))
+	var listed validationRules
+	if err := json.Unmarshal(rr.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	expectedSettings := []domain.RuleSetting{{Name: "distinct_options"}, {Name: "single_answer"}, {Name: "banned_words", Params: []string{"Obviously"}}}
+	if !reflect.DeepEqual(listed.Rules, expectedSettings) {
+		t.Errorf("rules returned, %+v, did not match %+v", listed.Rules, expectedSettings)
+	}
+
+	question := domain.Question{ID: 1, Body: "", Options: []domain.Option{
+		{Body: "East", Correct: true},
+		{Body: "east ", Correct: true},
+		{Body: "obviously west"},
+	}}
+
+	rr = httptest.NewRecorder()
+	questions(rr, organizationRequest(t, http.MethodPost, "/questions", "acme", buildBufJson(question, t)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	var problem Problem
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	expectedErrors := []FieldError{
+		{Field: "body", Code: "required", Message: "is required"},
+		{Field: "options[1].body", Code: "distinct", Message: "must differ from the other options"},
+		{Field: "options", Code: "single_answer", Message: "must have only one correct option"},
+		{Field: "options[2].body", Code: "banned_word", Param: "Obviously", Message: "must not contain the word Obviously"},
+	}
+	if !reflect.DeepEqual(problem.Errors, expectedErrors) {
+		t.Errorf("errors returned, %+v, did not match %+v", problem.Errors, expectedErrors)
+	}
+
+	// breaking only the rules is refused by the usecase
+	question.Body = "Where does the sun rise?"
+	for _, method := range []string{http.MethodPost, http.MethodPut} {
+		rr = httptest.NewRecorder()
+		questions(rr, organizationRequest(t, method, "/questions", "acme", buildBufJson(question, t)))
+		if rr.Code != http.StatusBadRequest {
+			t.Fatalf("Status code returned for %s, %d, did not match expected code %d: %s", method, rr.Code, http.StatusBadRequest, rr.Body.String())
+		}
+		problem = Problem{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(problem.Errors, expectedErrors[1:]) {
+			t.Errorf("errors returned for %s, %+v, did not match %+v", method, problem.Errors, expectedErrors[1:])
+		}
+	}
+
+	// translations are held to the same rules
+	translated := srv.guard(questionPermission, Server.handleQuestion)
+	valid := domain.Question{ID: 2, Body: "Where does the sun set?", Options: []domain.Option{{Body: "East"}, {Body: "West", Correct: true}}}
+	rr = httptest.NewRecorder()
+	questions(rr, organizationRequest(t, http.MethodPost, "/questions", "acme", buildBufJson(valid, t)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	rr = httptest.NewRecorder()
+	translated(rr, organizationRequest(t, http.MethodPut, "/questions/2/translations/es", "acme", bytes.NewBufferString(
+		`{"body":"¿Dónde se pone el sol?","options":[{"body":"Este"},{"body":"Obviously oeste","correct":true}]}`,
+	)))
+	if rr.Code != http.StatusBadRequest {
+		t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusBadRequest, rr.Body.String())
+	}
+	problem = Problem{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	expectedTranslationErrors := []FieldError{
+		{Field: "options[1].body", Code: "banned_word", Param: "Obviously", Message: "must not contain the word Obviously"},
+	}
+	if !reflect.DeepEqual(problem.Errors, expectedTranslationErrors) {
+		t.Errorf("errors returned, %+v, did not match %+v", problem.Errors, expectedTranslationErrors)
+	}
+	rr = httptest.NewRecorder()
+	translated(rr, organizationRequest(t, http.MethodPut, "/questions/2/translations/es", "acme", bytes.NewBufferString(
+		`{"body":"¿Dónde se pone el sol?","options":[{"body":"Este"},{"body":"Oeste","correct":true}]}`,
+	)))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	// the rules of acme do not apply to other organizations
+	rr = httptest.NewRecorder()
+	questions(rr, organizationRequest(t, http.MethodPost, "/questions", "globex", buildBufJson(question, t)))
+	if rr.Code != http.StatusOK {
+		t.Errorf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+}
@@ -5,46 +5,216 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/togglhire/backend-homework/domain"
 	"github.com/togglhire/backend-homework/usecase"
-
-	"github.com/go-playground/validator/v10"
 )
 
-const SRV_SHUTDOWN_TIMEOUT = 10
+// Timeouts bound how long the server waits, zero ones do not. Read covers the whole request and ReadHeader its
+// headers, Write lasts from the end of the headers to the end of the response, Idle is how long a kept alive
+// connection waits for the next request. Shutdown is how long requests being answered get to finish once the
+// server closes.
+type Timeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+	Shutdown   time.Duration
+}
+
+// Limits are the most bytes of body a request may send, larger ones are answered 413. Upload is the limit of
+// attachment uploads and Body of every other request, zero does not limit them.
+type Limits struct {
+	Body   int64
+	Upload int64
+}
+
+// Options are the optional dependencies of a server, the zero value serves plain HTTP/1.1 without rate limits,
+// metrics, timeouts or body limits and is always ready.
+type Options struct {
+	// Limiter rate limits requests.
+	Limiter *RateLimiter
+	// Metrics measures requests and serves them on /metrics.
+	Metrics *Metrics
+	// Readiness runs the checks of /readyz.
+	Readiness *Readiness
+	Timeouts  Timeouts
+	Limits    Limits
+	// Certificates serve HTTPS over HTTP/2 or HTTP/1.1.
+	Certificates *Certificates
+}
 
 type Server struct {
-	port      int
-	srv       *http.Server
-	questions usecase.Questions
+	port        int
+	srv         *http.Server
+	tenants     Tenants
+	auth        Authenticator
+	limiter     *RateLimiter
+	metrics     *Metrics
+	readiness   *Readiness
+	timeouts    Timeouts
+	limits      Limits
+	certs       *Certificates
+	questions   usecase.Questions
+	attachments usecase.Attachments
+	responses   usecase.Responses
+	generator   usecase.Generator
+	analytics   usecase.Analytics
+	adaptive    usecase.Adaptive
+	apiKeys     usecase.APIKeys
+	audit       usecase.Audit
+	rules       usecase.ValidationRules
+	// identity is the caller of the request the copy made by guard answers, zero otherwise
+	identity Identity
+}
+
+// Usecases groups everything the handlers delegate to.
+type Usecases struct {
+	Questions   usecase.Questions
+	Attachments usecase.Attachments
+	Responses   usecase.Responses
+	Generator   usecase.Generator
+	Analytics   usecase.Analytics
+	Adaptive    usecase.Adaptive
+	APIKeys     usecase.APIKeys
+	Audit       usecase.Audit
+	Rules       usecase.ValidationRules
 }
 
-func NewServer(ctx context.Context, port int, questions usecase.Questions) (context.Context, *Server) {
-	srv := Server{port: port, srv: &http.Server{Addr: fmt.Sprintf(":%d", port)}, questions: questions}
+// Tenants builds the usecases bound to the data of one organization, acting as actor.
+type Tenants func(organization string, actor domain.Actor) Usecases
+
+// NewServer serves the default organization unless a request names another one, see guard. What it does
+// without each of the options is described on Options.
+func NewServer(ctx context.Context, port int, tenants Tenants, auth Authenticator, opts Options) (context.Context, *Server) {
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		ReadTimeout:       opts.Timeouts.Read,
+		ReadHeaderTimeout: opts.Timeouts.ReadHeader,
+		WriteTimeout:      opts.Timeouts.Write,
+		IdleTimeout:       opts.Timeouts.Idle,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	if opts.Certificates != nil {
+		httpServer.TLSConfig = opts.Certificates.tlsConfig()
+	}
+	srv := Server{port: port, srv: httpServer, tenants: tenants, auth: auth, limiter: opts.Limiter, metrics: opts.Metrics,
+		readiness: opts.Readiness, timeouts: opts.Timeouts, limits: opts.Limits, certs: opts.Certificates}
+	srv = srv.withUsecases(tenants(domain.DefaultOrganization, domain.Actor{}))
 	return serverContext(ctx), &srv
 }
 
+func (s Server) withUsecases(usecases Usecases) Server {
+	s.questions = usecases.Questions
+	s.attachments = usecases.Attachments
+	s.responses = usecases.Responses
+	s.generator = usecases.Generator
+	s.analytics = usecases.Analytics
+	s.adaptive = usecases.Adaptive
+	s.apiKeys = usecases.APIKeys
+	s.audit = usecases.Audit
+	s.rules = usecases.Rules
+	return s
+}
+
+// guard authenticates and rate limits the request, checks its roles grant the permission it requires and
+// runs the handler on a copy of the server whose usecases only see the organization of the request and act
+// as its caller.
+func (s Server) guard(required requires, handler func(Server, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := requestID(w, r)
+		identity, status, err := s.auth.identify(r)
+		if s.limiter != nil {
+			decision := s.limiter.Allow(rateLimitKey(identity, r), isWrite(r))
+			writeRateLimitHeaders(w, decision)
+			if !decision.Allowed {
+				writeProblem(w, r, http.StatusTooManyRequests, "too many requests")
+				return
+			}
+		}
+		if err != nil {
+			writeProblem(w, r, status, err.Error())
+			return
+		}
+		if len(identity.Roles) == 0 && identity.Subject == "" {
+			writeProblem(w, r, http.StatusUnauthorized, errUnauthenticated.Error())
+			return
+		}
+		if permission := required(r); !identity.Can(permission) {
+			writeProblem(w, r, http.StatusForbidden, forbidden(identity, permission))
+			return
+		}
+		actor := domain.Actor{Subject: identity.Subject, RequestID: requestID, IP: clientIP(r)}
+		if actor.Subject == "" {
+			actor.Subject = ANONYMOUS_SUBJECT
+		}
+		scoped := s.withUsecases(s.tenants(identity.Organization, actor))
+		scoped.identity = identity
+		handler(scoped, w, r)
+	}
+}
+
+// Close reports not ready and lets load balancers drain traffic, then stops accepting connections and waits for
+// the requests being answered. Those still running after the shutdown timeout are cut off and Close returns
+// context.DeadlineExceeded.
 func (server *Server) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*SRV_SHUTDOWN_TIMEOUT)
-	defer cancel()
-	return server.srv.Shutdown(ctx)
+	server.readiness.drain()
+	ctx := context.Background()
+	if server.timeouts.Shutdown > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, server.timeouts.Shutdown)
+		defer cancel()
+	}
+	err := server.srv.Shutdown(ctx)
+	if err != nil {
+		server.srv.Close()
+		return fmt.Errorf("err waiting for requests to finish:%w", err)
+	}
+	slog.Info("HTTP server stopped")
+	return nil
 }
 
 func (s *Server) Run(ctx context.Context) error {
-	log.Println("HTTP server starting on port", s.port)
-
-	http.HandleFunc("/status", s.handleStatus)
-	http.HandleFunc("/questions", s.handleQuestions)
+	slog.Info("HTTP server starting", "port", s.port)
+
+	s.handle("/livez", s.handleLivez)
+	s.handle("/readyz", s.handleReadyz)
+	s.handle("/questions", s.guard(questionPermission, Server.handleQuestions))
+	s.handle("/questions/", s.guard(questionPermission, Server.handleQuestion))
+	s.handleLimited("/attachments", s.limits.Upload, s.guard(always(PermissionCreate), Server.handleAttachments))
+	s.handle("/attachments/", s.guard(readOrWrite(PermissionCreate), Server.handleAttachment))
+	s.handle("/tests/generate", s.guard(always(PermissionExport), Server.handleGenerateTest))
+	s.handle("/adaptive-sessions", s.guard(always(PermissionRespond), Server.handleAdaptiveSessions))
+	s.handle("/adaptive-sessions/", s.guard(always(PermissionRespond), Server.handleAdaptiveSession))
+	s.handle("/irt/calibrate", s.guard(always(PermissionUpdate), Server.handleCalibrate))
+	s.handle("/api-keys", s.guard(always(PermissionManageKeys), Server.handleAPIKeys))
+	s.handle("/api-keys/", s.guard(always(PermissionManageKeys), Server.handleAPIKey))
+	s.handle("/audit", s.guard(always(PermissionAudit), Server.handleAudit))
+	s.handle("/audit/verify", s.guard(always(PermissionAudit), Server.handleAuditVerify))
+	s.handle("/validation-rules", s.guard(readOrWrite(PermissionConfigure), Server.handleValidationRules))
+
+	if s.metrics != nil {
+		http.Handle(METRICS_PATH, s.metrics.Handler())
+	}
 
 	go func() {
-		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("err on http server: %s", err)
+		var err error
+		if s.certs != nil {
+			go s.certs.Watch(ctx)
+			err = s.srv.ListenAndServeTLS("", "")
+		} else {
+			err = s.srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			slog.Error("err on http server", "err", err)
 		}
 	}()
 
@@ -55,12 +225,15 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
-func (s Server) handleStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "invalid http method", http.StatusMethodNotAllowed)
-		return
-	}
-	w.WriteHeader(http.StatusOK)
+// handle registers handler on the pattern, measured, traced and logged as a route of its own. Request bodies
+// are limited to the body limit and panics answer 500.
+func (s *Server) handle(pattern string, handler http.HandlerFunc) {
+	s.handleLimited(pattern, s.limits.Body, handler)
+}
+
+// handleLimited is handle with bodies limited to limit bytes.
+func (s *Server) handleLimited(pattern string, limit int64, handler http.HandlerFunc) {
+	http.HandleFunc(pattern, withRequestID(traced(pattern, s.metrics.instrument(pattern, logged(pattern, recovered(limitBody(limit, handler)))))))
 }
 
 func (s Server) handleQuestions(w http.ResponseWriter, r *http.Request) {
@@ -72,50 +245,188 @@ func (s Server) handleQuestions(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPut:
 		s.updateQuestion(w, r)
 	default:
-		http.Error(w, "invalid http method", http.StatusMethodNotAllowed)
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+	}
+}
+
+func (s Server) handleQuestion(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/questions/"), "/")
+	if len(segments) == 1 && segments[0] == "summary" {
+		s.handleSummary(w, r)
+		return
+	}
+	if len(segments) == 1 && segments[0] == "stats" {
+		s.handleLibraryReport(w, r)
+		return
+	}
+
+	id, err := strconv.Atoi(segments[0])
+	if err != nil {
+		writeProblem(w, r, http.StatusNotFound, "invalid question id")
+		return
+	}
+
+	if len(segments) > 1 && segments[1] == "translations" {
+		s.handleTranslations(w, r, id, segments[2:])
+		return
+	}
+	if len(segments) == 2 && segments[1] == "candidate" {
+		s.handleCandidateView(w, r, id)
+		return
+	}
+	if len(segments) == 2 && segments[1] == "responses" {
+		s.handleResponses(w, r, id)
+		return
+	}
+	if len(segments) == 2 && segments[1] == "stats" {
+		s.handleQuestionStats(w, r, id)
+		return
+	}
+	if len(segments) > 1 {
+		writeProblem(w, r, http.StatusNotFound, "")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.getQuestion(w, r, id)
+	case http.MethodDelete:
+		s.deleteQuestion(w, r, id)
+	default:
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
 	}
 }
 
 func (s Server) listQuestions(w http.ResponseWriter, r *http.Request) {
 
-	questions := s.questions.GetAll()
+	filter, err := parseQuestionFilter(r.URL.Query())
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	questions, err := s.questions.ListLocalized(r.Context(), filter, acceptedLocales(r.Header.Get("Accept-Language")))
+	if err != nil {
+		writeError(w, r, "Internal error listing questions", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept-Language")
+
+	err = json.NewEncoder(w).Encode(questions)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response list questions", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) handleSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	ids, err := parseIDs(r.URL.Query().Get("ids"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := s.questions.Summary(r.Context(), ids)
+
+	if errors.Is(err, domain.ErrNoQuestionFound) {
+		writeProblem(w, r, http.StatusNotFound, "some of the questions do not exist")
+		return
+	}
+
+	if err != nil {
+		writeError(w, r, "Internal error summarizing questions", err)
+		return
+	}
 
 	w.Header().Add("Content-Type", "application/json")
 
-	err := json.NewEncoder(w).Encode(questions)
+	err = json.NewEncoder(w).Encode(summary)
 	if err != nil {
-		log.Println("err encoding json response list questions", err)
+		slog.ErrorContext(r.Context(), "err encoding json response summary", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
+func (s Server) getQuestion(w http.ResponseWriter, r *http.Request, id int) {
+
+	question, locale, err := s.questions.GetLocalized(r.Context(), id, acceptedLocales(r.Header.Get("Accept-Language")))
+
+	if err != nil {
+		writeError(w, r, "Internal error getting question", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Vary", "Accept-Language")
+	if locale != "" {
+		w.Header().Add("Content-Language", locale)
+	}
+
+	err = json.NewEncoder(w).Encode(question)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response get question", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) deleteQuestion(w http.ResponseWriter, r *http.Request, id int) {
+
+	err := s.questions.Delete(r.Context(), id)
+
+	if err != nil {
+		writeError(w, r, "Internal error deleting question", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func (s Server) addQuestion(w http.ResponseWriter, r *http.Request) {
 
 	var question domain.Question
 	if r.Body == nil {
-		http.Error(w, "Please send a request body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
 		return
 	}
 
 	if r.Header.Get("Content-Type") != "application/json" {
-		http.Error(w, "Incorrect media type", http.StatusUnsupportedMediaType)
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
 		return
 	}
+	decoding := step(r, "decode question")
 	err := json.NewDecoder(r.Body).Decode(&question)
+	decoding.End()
 	if err != nil {
-		http.Error(w, "failed to decode json body", http.StatusBadRequest)
+		writeBodyError(w, r, "failed to decode json body", err)
 		return
 	}
 
-	if err := validateQuestionInput(question); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	validating := step(r, "validate question")
+	err = s.validateQuestion(r.Context(), question)
+	validating.End()
+	if errors.As(err, new(fieldErrors)) {
+		writeInvalid(w, r, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, "Internal error validating question", err)
 		return
 	}
 
-	err = s.questions.Add(question)
+	err = s.questions.Add(r.Context(), question)
+	if errors.Is(err, domain.ErrNoAttachmentFound) {
+		writeProblem(w, r, http.StatusBadRequest, "unknown attachment referenced")
+		return
+	}
 	if err != nil {
-		log.Println("Internal error adding question", err)
-		http.Error(w, "Internal error adding question", http.StatusInternalServerError)
+		writeError(w, r, "Internal error adding question", err)
 		return
 	}
 
@@ -123,7 +434,7 @@ func (s Server) addQuestion(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(question)
 	if err != nil {
-		log.Println("err encoding json response add question", err)
+		slog.ErrorContext(r.Context(), "err encoding json response add question", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
@@ -132,35 +443,43 @@ func (s Server) updateQuestion(w http.ResponseWriter, r *http.Request) {
 
 	var question domain.Question
 	if r.Body == nil {
-		http.Error(w, "Please send a request body", http.StatusBadRequest)
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
 		return
 	}
 
 	if r.Header.Get("Content-Type") != "application/json" {
-		http.Error(w, "Incorrect media type", http.StatusUnsupportedMediaType)
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
 		return
 	}
+	decoding := step(r, "decode question")
 	err := json.NewDecoder(r.Body).Decode(&question)
+	decoding.End()
 	if err != nil {
-		http.Error(w, "failed to decode json body", http.StatusBadRequest)
+		writeBodyError(w, r, "failed to decode json body", err)
 		return
 	}
 
-	if err := validateQuestionInput(question); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	validating := step(r, "validate question")
+	err = s.validateQuestion(r.Context(), question)
+	validating.End()
+	if errors.As(err, new(fieldErrors)) {
+		writeInvalid(w, r, err)
+		return
+	}
+	if err != nil {
+		writeError(w, r, "Internal error validating question", err)
 		return
 	}
 
-	err = s.questions.Update(question)
+	err = s.questions.Update(r.Context(), question)
 
-	if errors.Is(err, domain.ErrNoQuestionFound) {
-		w.WriteHeader(http.StatusNotFound)
+	if errors.Is(err, domain.ErrNoAttachmentFound) {
+		writeProblem(w, r, http.StatusBadRequest, "unknown attachment referenced")
 		return
 	}
 
 	if err != nil {
-		log.Println("Internal error updating question", err)
-		http.Error(w, "Internal error updating question", http.StatusInternalServerError)
+		writeError(w, r, "Internal error updating question", err)
 		return
 	}
 
@@ -168,37 +487,45 @@ func (s Server) updateQuestion(w http.ResponseWriter, r *http.Request) {
 
 	err = json.NewEncoder(w).Encode(question)
 	if err != nil {
-		log.Println("err encoding json response update question", err)
+		slog.ErrorContext(r.Context(), "err encoding json response update question", "err", err)
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
 
-func validateQuestionInput(question domain.Question) error {
-	validator := validator.New()
-	if err := validator.Struct(question); err != nil {
-		return err
-	}
-
-	foundOneAnswer := false
-	for _, opt := range question.Options {
-		if opt.Correct {
-			foundOneAnswer = true
-			break
+// validateQuestion checks the struct tags of the question. The usecase checks the business rules of the
+// organization on write, they are only checked here when the tags already reject the question, so every rule
+// it breaks is reported at once.
+func (s Server) validateQuestion(ctx context.Context, question domain.Question) error {
+	var fields fieldErrors
+	if err := validate.Struct(question); err != nil {
+		if !errors.As(validationErrors(err), &fields) {
+			return err
 		}
 	}
-	if !foundOneAnswer {
-		return fmt.Errorf("err question does not have answer")
+	if len(fields) == 0 {
+		return nil
 	}
 
-	return nil
+	err := s.questions.Validate(ctx, question)
+	var violations fieldErrors
+	if errors.As(validationErrors(err), &violations) {
+		fields = append(fields, violations...)
+	} else if err != nil {
+		return err
+	}
+	return fields
 }
 
+// serverContext is cancelled on SIGINT or SIGTERM. The handler is removed after the first, so a second one
+// kills the process without waiting for the shutdown.
 func serverContext(ctx context.Context) context.Context {
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	ctx, cancel := context.WithCancel(ctx)
 	go func() {
-		<-c
+		sig := <-c
+		signal.Stop(c)
+		slog.Info("shutting down", "signal", sig.String())
 		cancel()
 	}()
 
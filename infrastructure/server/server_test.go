@@ -6,13 +6,17 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/blob"
 	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
 	"github.com/togglhire/backend-homework/usecase"
+	"gorm.io/gorm"
 )
 
 func buildBufJson(question domain.Question, t *testing.T) *bytes.Buffer {
@@ -23,51 +27,66 @@ func buildBufJson(question domain.Question, t *testing.T) *bytes.Buffer {
 	return bytes.NewBuffer(input)
 }
 
-func TestServer_handleStatus(t *testing.T) {
-	db := sql.SetupSQLConnection("test.db")
-	defer os.Remove("test.db")
-	_, srv := NewServer(context.Background(), 0, usecase.NewQuestions(sql.NewRepo(db)))
+const TEST_TOKEN_SECRET = "test-secret"
 
-	type args struct {
-		r *http.Request
-	}
-	tests := []struct {
-		name           string
-		args           args
-		expectedStatus int
-	}{
-		{name: "Get status should return OK", args: args{r: httptest.NewRequest(http.MethodGet, "/status", nil)}, expectedStatus: http.StatusOK},
-		{name: "Status with invalid method should not be OK", args: args{r: httptest.NewRequest(http.MethodPost, "/status", nil)}, expectedStatus: http.StatusMethodNotAllowed},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			rr := httptest.NewRecorder()
-			srv.handleStatus(rr, tt.args.r)
-			if rr.Result().StatusCode != tt.expectedStatus {
-				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
-			}
-		})
+// setupTestDB migrates a fresh database for a test.
+func setupTestDB(t *testing.T) *gorm.DB {
+	return sqltest.Open(t)
+}
+
+func buildServer(repo sql.Repository, t *testing.T) *Server {
+	return buildServerWithQuestions(repo, nil, t)
+}
+
+// buildServerWithQuestions serves the questions of questions instead of those of repo, when it is not nil.
+func buildServerWithQuestions(repo sql.Repository, questions domain.QuestionRepository, t *testing.T) *Server {
+	blobs, err := blob.NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
 	}
+	_, srv := NewServer(context.Background(), 0, func(organization string, actor domain.Actor) Usecases {
+		scoped := repo.ForOrganization(organization).WithActor(actor)
+		var scopedQuestions domain.QuestionRepository = scoped
+		if questions != nil {
+			scopedQuestions = questions
+		}
+		attachments := usecase.NewAttachments(scoped, blobs, 1024)
+		responses := usecase.NewResponses(scopedQuestions, scoped)
+		rules := usecase.NewValidationRules(scoped, domain.DefaultRuleRegistry())
+		return Usecases{
+			Questions:   usecase.NewQuestions(scopedQuestions, scoped, attachments, rules),
+			Attachments: attachments,
+			Responses:   responses,
+			Generator:   usecase.NewGenerator(scopedQuestions),
+			Analytics:   usecase.NewAnalytics(scopedQuestions, scoped),
+			Adaptive: usecase.NewAdaptive(scopedQuestions, responses, scoped, scoped, usecase.AdaptiveConfig{
+				MinResponses: 5, MaxItems: 5, StandardErrorThreshold: 0.3,
+			}),
+			APIKeys: usecase.NewAPIKeys(scoped),
+			Audit:   usecase.NewAudit(scoped),
+			Rules:   rules,
+		}
+	}, NewAuthenticator(TEST_TOKEN_SECRET, RoleAdmin, usecase.NewAPIKeys(repo)), Options{})
+	return srv
 }
 
 func TestServer_ListQuestionsOrderShouldBeStable(t *testing.T) {
-	db := sql.SetupSQLConnection("test.db")
-	defer os.Remove("test.db")
+	db := setupTestDB(t)
 	repo := sql.NewRepo(db)
 
-	_ = repo.Add(domain.Question{ID: 1, Body: "one", Options: []domain.Option{
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: []domain.Option{
 		{Body: "option one", Correct: false},
 	}})
 
-	_ = repo.Add(domain.Question{ID: 3, Body: "three", Options: []domain.Option{
+	_ = repo.Add(context.Background(), domain.Question{ID: 3, Body: "three", Options: []domain.Option{
 		{Body: "option one for question 3", Correct: false},
 	}})
 
-	_ = repo.Add(domain.Question{ID: 2, Body: "two", Options: []domain.Option{
+	_ = repo.Add(context.Background(), domain.Question{ID: 2, Body: "two", Options: []domain.Option{
 		{Body: "option one for question 2", Correct: false},
 	}})
 
-	_, srv := NewServer(context.Background(), 0, usecase.NewQuestions(repo))
+	srv := buildServer(repo, t)
 	rr := httptest.NewRecorder()
 	r := httptest.NewRequest(http.MethodGet, "/questions", nil)
 	srv.listQuestions(rr, r)
@@ -100,9 +119,8 @@ func TestServer_ListQuestionsOrderShouldBeStable(t *testing.T) {
 }
 
 func TestServer_addQuestion(t *testing.T) {
-	db := sql.SetupSQLConnection("test.db")
-	defer os.Remove("test.db")
-	_, srv := NewServer(context.Background(), 0, usecase.NewQuestions(sql.NewRepo(db)))
+	db := setupTestDB(t)
+	srv := buildServer(sql.NewRepo(db), t)
 
 	emptyOptQuestion := domain.Question{ID: 1, Body: "hello", Options: []domain.Option{}}
 	validQuestion := domain.Question{ID: 1, Body: "hello",
@@ -120,6 +138,22 @@ func TestServer_addQuestion(t *testing.T) {
 			{Body: "option a", Correct: true},
 		}}
 
+	questionWithInvalidDifficulty := domain.Question{ID: 12, Body: "meaning of life", Difficulty: "impossible",
+		Options: []domain.Option{
+			{Body: "option a"}, {Body: "option b", Correct: true},
+		}}
+
+	questionWithNegativePoints := domain.Question{ID: 13, Body: "meaning of life", Points: -1,
+		Options: []domain.Option{
+			{Body: "option a"}, {Body: "option b", Correct: true},
+		}}
+
+	questionWithMetadata := domain.Question{ID: 14, Body: "meaning of life", Difficulty: domain.DifficultyHard,
+		EstimatedSeconds: 90, Points: 5,
+		Options: []domain.Option{
+			{Body: "option a"}, {Body: "option b", Correct: true},
+		}}
+
 	type args struct {
 		r *http.Request
 	}
@@ -139,10 +173,10 @@ func TestServer_addQuestion(t *testing.T) {
 			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
 				buildBufJson(validQuestion, t))},
 			expectedStatus: http.StatusOK},
-		{name: "create same question id should fail with 500",
+		{name: "create same question id should conflict",
 			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
 				buildBufJson(validQuestion, t))},
-			expectedStatus: http.StatusInternalServerError},
+			expectedStatus: http.StatusConflict},
 		{name: "create question with no correct answer should fail with 400",
 			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
 				buildBufJson(questionWithNoCorrectAnswer, t))},
@@ -151,6 +185,18 @@ func TestServer_addQuestion(t *testing.T) {
 			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
 				buildBufJson(questionWithOnlyOneAnswer, t))},
 			expectedStatus: http.StatusBadRequest},
+		{name: "create question with invalid difficulty should fail with 400",
+			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
+				buildBufJson(questionWithInvalidDifficulty, t))},
+			expectedStatus: http.StatusBadRequest},
+		{name: "create question with negative points should fail with 400",
+			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
+				buildBufJson(questionWithNegativePoints, t))},
+			expectedStatus: http.StatusBadRequest},
+		{name: "create question with metadata should be OK",
+			args: args{r: httptest.NewRequest(http.MethodPost, "/questions",
+				buildBufJson(questionWithMetadata, t))},
+			expectedStatus: http.StatusOK},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -165,10 +211,9 @@ func TestServer_addQuestion(t *testing.T) {
 }
 
 func TestServer_updateQuestion(t *testing.T) {
-	db := sql.SetupSQLConnection("test.db")
-	defer os.Remove("test.db")
+	db := setupTestDB(t)
 	repo := sql.NewRepo(db)
-	_, srv := NewServer(context.Background(), 0, usecase.NewQuestions(repo))
+	srv := buildServer(repo, t)
 
 	emptyOptQuestion := domain.Question{ID: 1, Body: "hello", Options: []domain.Option{}}
 	validQuestionNonExistent := domain.Question{ID: 1, Body: "hello",
@@ -180,7 +225,7 @@ func TestServer_updateQuestion(t *testing.T) {
 		Options: []domain.Option{
 			{Body: "option a"}, {Body: "option b", Correct: true},
 		}}
-	_ = repo.Add(validQuestionExistent)
+	_ = repo.Add(context.Background(), validQuestionExistent)
 
 	type args struct {
 		r *http.Request
@@ -217,3 +262,172 @@ func TestServer_updateQuestion(t *testing.T) {
 		})
 	}
 }
+
+func TestServer_getAndDeleteQuestion(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 5, Body: "five", Options: []domain.Option{
+		{Body: "option a"}, {Body: "option b", Correct: true},
+	}})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{name: "Get existent question should be OK", method: http.MethodGet, path: "/questions/5", expectedStatus: http.StatusOK},
+		{name: "Get non existent question should throw 404", method: http.MethodGet, path: "/questions/6", expectedStatus: http.StatusNotFound},
+		{name: "Get invalid id should throw 404", method: http.MethodGet, path: "/questions/five", expectedStatus: http.StatusNotFound},
+		{name: "Delete existent question should give 204", method: http.MethodDelete, path: "/questions/5", expectedStatus: http.StatusNoContent},
+		{name: "Delete same question again should throw 404", method: http.MethodDelete, path: "/questions/5", expectedStatus: http.StatusNotFound},
+		{name: "Get deleted question should throw 404", method: http.MethodGet, path: "/questions/5", expectedStatus: http.StatusNotFound},
+		{name: "Invalid method should not be allowed", method: http.MethodPatch, path: "/questions/5", expectedStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.handleQuestion(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+func TestServer_listQuestionsFilterAndSort(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	options := []domain.Option{{Body: "option a"}, {Body: "option b", Correct: true}}
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: options, Difficulty: domain.DifficultyHard, EstimatedSeconds: 120, Points: 10})
+	_ = repo.Add(context.Background(), domain.Question{ID: 2, Body: "two", Options: options, Difficulty: domain.DifficultyEasy, EstimatedSeconds: 30, Points: 2})
+	_ = repo.Add(context.Background(), domain.Question{ID: 3, Body: "three", Options: options, Difficulty: domain.DifficultyMedium, EstimatedSeconds: 60, Points: 5})
+	_ = repo.Add(context.Background(), domain.Question{ID: 4, Body: "four", Options: options, Difficulty: domain.DifficultyEasy, EstimatedSeconds: 45, Points: 2})
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedIDs    []int
+	}{
+		{name: "no filters should return newest first", query: "", expectedStatus: http.StatusOK, expectedIDs: []int{4, 3, 2, 1}},
+		{name: "filter by difficulty", query: "?difficulty=easy", expectedStatus: http.StatusOK, expectedIDs: []int{4, 2}},
+		{name: "filter by several difficulties", query: "?difficulty=easy,hard", expectedStatus: http.StatusOK, expectedIDs: []int{4, 2, 1}},
+		{name: "filter by points range", query: "?min_points=3&max_points=10", expectedStatus: http.StatusOK, expectedIDs: []int{3, 1}},
+		{name: "filter by estimated time", query: "?max_estimated_seconds=45", expectedStatus: http.StatusOK, expectedIDs: []int{4, 2}},
+		{name: "sort by difficulty follows the scale", query: "?sort=difficulty", expectedStatus: http.StatusOK, expectedIDs: []int{4, 2, 3, 1}},
+		{name: "sort descending by several fields", query: "?sort=-points,estimated_seconds", expectedStatus: http.StatusOK, expectedIDs: []int{1, 3, 2, 4}},
+		{name: "invalid difficulty should fail with 400", query: "?difficulty=impossible", expectedStatus: http.StatusBadRequest},
+		{name: "invalid bound should fail with 400", query: "?min_points=many", expectedStatus: http.StatusBadRequest},
+		{name: "unknown sort field should fail with 400", query: "?sort=body", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.listQuestions(rr, httptest.NewRequest(http.MethodGet, "/questions"+tt.query, nil))
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response []domain.Question
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatal(err)
+			}
+			ids := make([]int, 0, len(response))
+			for _, q := range response {
+				ids = append(ids, q.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("ids returned, %v, did not match expected ids %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+}
+
+func TestServer_summary(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	options := []domain.Option{{Body: "option a"}, {Body: "option b", Correct: true}}
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: options, EstimatedSeconds: 120, Points: 10})
+	_ = repo.Add(context.Background(), domain.Question{ID: 2, Body: "two", Options: options, EstimatedSeconds: 30, Points: 2})
+	_ = repo.Add(context.Background(), domain.Question{ID: 3, Body: "three", Options: options, EstimatedSeconds: 60, Points: 5})
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedSummary domain.Summary
+	}{
+		{name: "summary of chosen questions", query: "?ids=1,3", expectedStatus: http.StatusOK,
+			expectedSummary: domain.Summary{Questions: 2, TotalEstimatedSeconds: 180, TotalPoints: 15}},
+		{name: "repeated ids should count once", query: "?ids=2,2", expectedStatus: http.StatusOK,
+			expectedSummary: domain.Summary{Questions: 1, TotalEstimatedSeconds: 30, TotalPoints: 2}},
+		{name: "non existent question should throw 404", query: "?ids=1,4", expectedStatus: http.StatusNotFound},
+		{name: "missing ids should fail with 400", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "invalid ids should fail with 400", query: "?ids=one", expectedStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/summary"+tt.query, nil))
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var summary domain.Summary
+			if err := json.Unmarshal(rr.Body.Bytes(), &summary); err != nil {
+				t.Fatal(err)
+			}
+			if summary != tt.expectedSummary {
+				t.Errorf("summary returned, %+v, did not match expected %+v", summary, tt.expectedSummary)
+			}
+		})
+	}
+}
+
+func TestServer_queryDeadline(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "one", Options: []domain.Option{
+		{Body: "a", Correct: true}, {Body: "b"},
+	}})
+	srv := buildServer(repo, t)
+	timedOut := buildServer(repo.WithQueryTimeout(time.Nanosecond), t)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		r              *http.Request
+		expectedStatus int
+	}{
+		{"list past the query timeout", timedOut.handleQuestions, httptest.NewRequest(http.MethodGet, "/questions", nil), http.StatusGatewayTimeout},
+		{"get past the query timeout", timedOut.handleQuestion, httptest.NewRequest(http.MethodGet, "/questions/1", nil), http.StatusGatewayTimeout},
+		{"delete past the query timeout", timedOut.handleQuestion, httptest.NewRequest(http.MethodDelete, "/questions/1", nil), http.StatusGatewayTimeout},
+		{"client gone", srv.handleQuestions, httptest.NewRequest(http.MethodGet, "/questions", nil).WithContext(canceled), http.StatusServiceUnavailable},
+		{"in time", srv.handleQuestion, httptest.NewRequest(http.MethodGet, "/questions/1", nil), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			tt.handler(rr, tt.r)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d: %s", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+		})
+	}
+}
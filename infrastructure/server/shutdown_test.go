@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func TestServer_closeWaitsForRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		shutdown    time.Duration
+		handling    time.Duration
+		expectedErr error
+	}{
+		{"request finishing", time.Second, 50 * time.Millisecond, nil},
+		{"no shutdown timeout", 0, 50 * time.Millisecond, nil},
+		{"request outlasting the timeout", 50 * time.Millisecond, time.Second, context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			srv := buildServer(sql.NewRepo(db), t)
+			srv.timeouts = Timeouts{Shutdown: tt.shutdown}
+
+			started, finished := make(chan struct{}), make(chan struct{})
+			srv.srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				close(started)
+				defer close(finished)
+				select {
+				case <-time.After(tt.handling):
+				case <-r.Context().Done():
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go srv.srv.Serve(listener)
+
+			responded := make(chan error, 1)
+			go func() {
+				resp, err := http.Get("http://" + listener.Addr().String())
+				if err == nil {
+					resp.Body.Close()
+				}
+				responded <- err
+			}()
+			<-started
+
+			err = srv.Close()
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("Close returned %v, expected %v", err, tt.expectedErr)
+			}
+			if tt.expectedErr != nil {
+				<-finished
+				return
+			}
+			select {
+			case <-finished:
+			default:
+				t.Fatal("Close returned before the request being answered finished")
+			}
+			if err := <-responded; err != nil {
+				t.Errorf("request in flight during close failed: %v", err)
+			}
+			if _, err := http.Get("http://" + listener.Addr().String()); err == nil {
+				t.Errorf("server still accepts requests after close")
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+func (s Server) handleGenerateTest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+		return
+	}
+
+	var spec domain.TestSpec
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&spec)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	if err := validate.Struct(spec); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+
+	test, err := s.generator.Generate(r.Context(), spec)
+
+	if errors.Is(err, domain.ErrUnsatisfiableConstraints) {
+		writeProblem(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	if err != nil {
+		writeError(w, r, "Internal error generating test", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(test)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response generate test", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func generateTest(srv *Server, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/tests/generate", bytes.NewBufferString(body))
+	r.Header.Add("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+	srv.handleGenerateTest(rr, r)
+	return rr
+}
+
+func TestServer_generateTest(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	options := []domain.Option{{Body: "option a"}, {Body: "option b", Correct: true}}
+	difficulties := []domain.Difficulty{domain.DifficultyEasy, domain.DifficultyMedium, domain.DifficultyHard}
+	for i := 1; i <= 30; i++ {
+		tags := []string{"go"}
+		if i%5 == 0 {
+			tags = []string{"sql"}
+		}
+		_ = repo.Add(context.Background(), domain.Question{ID: i, Body: "question", Options: options, Tags: tags,
+			Difficulty: difficulties[i%3], EstimatedSeconds: 30 * (1 + i%4), Points: 1 + i%3})
+	}
+
+	tests := []struct {
+		name                 string
+		body                 string
+		expectedStatus       int
+		expectedDifficulties map[domain.Difficulty]int
+		maxTotalSeconds      int
+	}{
+		{name: "counts per difficulty should be exact", expectedStatus: http.StatusOK,
+			body:                 `{"count":12,"tags":["go"],"difficulties":{"easy":3,"medium":5,"hard":4},"seed":1}`,
+			expectedDifficulties: map[domain.Difficulty]int{domain.DifficultyEasy: 3, domain.DifficultyMedium: 5, domain.DifficultyHard: 4}},
+		{name: "rest of the questions should come from other difficulties", expectedStatus: http.StatusOK,
+			body:                 `{"count":10,"difficulties":{"easy":4},"seed":2}`,
+			expectedDifficulties: map[domain.Difficulty]int{domain.DifficultyEasy: 4}},
+		{name: "total time should stay under the limit", expectedStatus: http.StatusOK,
+			body:            `{"count":8,"tags":["go"],"max_total_seconds":330,"seed":3}`,
+			maxTotalSeconds: 330},
+		{name: "too many questions for a tag should fail with 422", expectedStatus: http.StatusUnprocessableEntity,
+			body: `{"count":7,"tags":["sql"],"seed":4}`},
+		{name: "too little time should fail with 422", expectedStatus: http.StatusUnprocessableEntity,
+			body: `{"count":8,"tags":["go"],"max_total_seconds":200,"seed":5}`},
+		{name: "difficulties over the count should fail with 422", expectedStatus: http.StatusUnprocessableEntity,
+			body: `{"count":2,"difficulties":{"easy":3},"seed":6}`},
+		{name: "unknown difficulty should fail with 400", expectedStatus: http.StatusBadRequest,
+			body: `{"count":2,"difficulties":{"trivial":1}}`},
+		{name: "missing count should fail with 400", expectedStatus: http.StatusBadRequest,
+			body: `{"tags":["go"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := generateTest(srv, tt.body)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Result().StatusCode, tt.expectedStatus, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var test domain.Test
+			if err := json.Unmarshal(rr.Body.Bytes(), &test); err != nil {
+				t.Fatal(err)
+			}
+			var spec domain.TestSpec
+			_ = json.Unmarshal([]byte(tt.body), &spec)
+			if len(test.Questions) != spec.Count {
+				t.Errorf("questions returned, %d, did not match expected count %d", len(test.Questions), spec.Count)
+			}
+
+			seen := make(map[int]bool)
+			counts := make(map[domain.Difficulty]int)
+			for _, question := range test.Questions {
+				if seen[question.ID] {
+					t.Errorf("question %d picked twice", question.ID)
+				}
+				seen[question.ID] = true
+				if _, ok := tt.expectedDifficulties[question.Difficulty]; ok {
+					counts[question.Difficulty]++
+				}
+				for _, tag := range spec.Tags {
+					if !reflect.DeepEqual(question.Tags, []string{tag}) {
+						t.Errorf("question %d tags, %v, did not include %s", question.ID, question.Tags, tag)
+					}
+				}
+			}
+			if tt.expectedDifficulties != nil && !reflect.DeepEqual(counts, tt.expectedDifficulties) {
+				t.Errorf("difficulties returned, %v, did not match expected %v", counts, tt.expectedDifficulties)
+			}
+			if tt.maxTotalSeconds > 0 && test.TotalEstimatedSeconds > tt.maxTotalSeconds {
+				t.Errorf("total seconds, %d, went over the limit %d", test.TotalEstimatedSeconds, tt.maxTotalSeconds)
+			}
+		})
+	}
+
+	first := generateTest(srv, `{"count":10,"difficulties":{"medium":3},"max_total_seconds":600,"seed":42}`)
+	second := generateTest(srv, `{"count":10,"difficulties":{"medium":3},"max_total_seconds":600,"seed":42}`)
+	if first.Body.String() != second.Body.String() {
+		t.Errorf("same seed should generate the same test, got %s and %s", first.Body.String(), second.Body.String())
+	}
+
+	var unseeded domain.Test
+	if err := json.Unmarshal(generateTest(srv, `{"count":3}`).Body.Bytes(), &unseeded); err != nil {
+		t.Fatal(err)
+	}
+	replayed := generateTest(srv, `{"count":3,"seed":`+jsonInt(unseeded.Seed)+`}`)
+	var replay domain.Test
+	if err := json.Unmarshal(replayed.Body.Bytes(), &replay); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unseeded, replay) {
+		t.Errorf("returned seed should reproduce the test")
+	}
+}
+
+func jsonInt(n int64) string {
+	b, _ := json.Marshal(n)
+	return string(b)
+}
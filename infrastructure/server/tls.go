@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Certificates serves the certificate and key of two PEM files. They are loaded again once either file changes,
+// so renewed certificates are served without a restart. While the new files do not load the old certificate
+// is kept.
+type Certificates struct {
+	certFile       string
+	keyFile        string
+	reloadInterval time.Duration
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+// NewCertificates loads the certificate and key, Watch looks for changes every reloadInterval.
+func NewCertificates(certFile, keyFile string, reloadInterval time.Duration) (*Certificates, error) {
+	c := &Certificates{certFile: certFile, keyFile: keyFile, reloadInterval: reloadInterval}
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate is the certificate to answer TLS handshakes with.
+func (c *Certificates) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.cert, nil
+}
+
+// Watch reloads the certificate when its files change, until ctx is done. A zero interval does not watch.
+func (c *Certificates) Watch(ctx context.Context) {
+	if c.reloadInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(c.reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := c.reload()
+			if err != nil {
+				slog.Error("err reloading tls certificate, keeping the previous one", "err", err)
+			} else if reloaded {
+				slog.Info("tls certificate reloaded", "cert_file", c.certFile)
+			}
+		}
+	}
+}
+
+// reload loads the files when either was modified since they were last loaded, and tells if it did.
+func (c *Certificates) reload() (bool, error) {
+	modified, err := lastModified(c.certFile, c.keyFile)
+	if err != nil {
+		return false, err
+	}
+	c.mu.RLock()
+	unchanged := c.cert != nil && !modified.After(c.modified)
+	c.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("err loading tls certificate:%w", err)
+	}
+	c.mu.Lock()
+	c.cert, c.modified = &cert, modified
+	c.mu.Unlock()
+	return true, nil
+}
+
+// tlsConfig offers HTTP/2 before HTTP/1.1 and refuses versions older than TLS 1.2.
+func (c *Certificates) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"h2", "http/1.1"},
+		GetCertificate: c.GetCertificate,
+	}
+}
+
+// lastModified is the latest modification time of the files, links are followed so swapped links count.
+func lastModified(files ...string) (time.Time, error) {
+	var latest time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("err reading tls file:%w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// writeCertificate writes a self signed certificate for localhost named commonName, and its key.
+func writeCertificate(certFile, keyFile, commonName string, t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func servedCommonName(certs *Certificates, t *testing.T) string {
+	cert, err := certs.GetCertificate(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestServer_tls(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(certFile, keyFile, "questions", t)
+	certs, err := NewCertificates(certFile, keyFile, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, srv := NewServer(context.Background(), 0, func(string, domain.Actor) Usecases { return Usecases{} },
+		Authenticator{}, Options{Timeouts: Timeouts{ReadHeader: time.Second}, Certificates: certs})
+	srv.srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.srv.ServeTLS(listener, "", "")
+	t.Cleanup(func() { srv.srv.Close() })
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
+		ForceAttemptHTTP2: true,
+	}}
+	resp, err := client.Get("https://" + listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 {
+		t.Errorf("protocol answered, %s, did not match expected HTTP/2.0", resp.Proto)
+	}
+	if name := resp.TLS.PeerCertificates[0].Subject.CommonName; name != "questions" {
+		t.Errorf("certificate served, %s, did not match expected questions", name)
+	}
+}
+
+func TestCertificates_reload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	writeCertificate(certFile, keyFile, "first", t)
+	certs, err := NewCertificates(certFile, keyFile, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if reloaded, err := certs.reload(); err != nil || reloaded {
+		t.Fatalf("unchanged files reloaded %t, %v, expected no reload", reloaded, err)
+	}
+
+	writeCertificate(certFile, keyFile, "renewed", t)
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if reloaded, err := certs.reload(); err != nil || !reloaded {
+		t.Fatalf("renewed files reloaded %t, %v, expected a reload", reloaded, err)
+	}
+	if name := servedCommonName(certs, t); name != "renewed" {
+		t.Errorf("certificate served, %s, did not match expected renewed", name)
+	}
+
+	if err := os.WriteFile(keyFile, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	later = later.Add(time.Minute)
+	if err := os.Chtimes(keyFile, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := certs.reload(); err == nil {
+		t.Errorf("broken key reloaded without error")
+	}
+	if name := servedCommonName(certs, t); name != "renewed" {
+		t.Errorf("certificate served after a broken reload, %s, did not match expected renewed", name)
+	}
+}
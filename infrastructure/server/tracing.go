@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TRACER_NAME = "github.com/togglhire/backend-homework/infrastructure/server"
+
+// tracer is looked up on every span, so installing another provider, as tests do, takes effect at once.
+func tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// traced answers each request of route in a span of its own, a child of the trace of the caller when the request
+// carries a traceparent header.
+func traced(route string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer().Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPServerAttributesFromHTTPRequest("", route, r)...),
+		)
+		defer span.End()
+
+		recorder := &statusRecorder{ResponseWriter: w}
+		handler(recorder, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPAttributesFromHTTPStatusCode(recorder.status())...)
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCodeAndSpanKind(recorder.status(), trace.SpanKindServer))
+	}
+}
+
+// step traces a part of answering a request that is not a usecase call, such as decoding its body.
+func step(r *http.Request, name string) trace.Span {
+	_, span := tracer().Start(r.Context(), name)
+	return span
+}
@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a provider keeping every span in memory until the end of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestServer_tracing(t *testing.T) {
+	recorder := recordSpans(t)
+	db := setupTestDB(t)
+	if err := sql.RegisterTracing(db); err != nil {
+		t.Fatal(err)
+	}
+	repo := sql.NewRepo(db)
+	question := domain.Question{ID: 1, Body: "hello", Options: []domain.Option{
+		{Body: "option a"}, {Body: "option b", Correct: true},
+	}}
+	if err := repo.Add(context.Background(), question); err != nil {
+		t.Fatal(err)
+	}
+	srv := buildServer(repo, t)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	r := httptest.NewRequest(http.MethodPut, "/questions", buildBufJson(question, t))
+	r.Header.Add("Content-Type", "application/json")
+	r.Header.Add("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	traced("/questions", srv.handleQuestions)(rr, r)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	var statements []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() != traceID {
+			t.Errorf("span %s is not part of the trace of the caller", span.Name())
+		}
+		if span.SpanKind() == trace.SpanKindClient {
+			statements = append(statements, span)
+			continue
+		}
+		spans[span.Name()] = span
+	}
+
+	request, ok := spans["PUT /questions"]
+	if !ok {
+		t.Fatalf("no span for the request in %v", spans)
+	}
+	if request.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("request span has parent %s, expected the span of the caller", request.Parent().SpanID())
+	}
+	for _, name := range []string{"decode question", "validate question", "Questions.Update"} {
+		span, ok := spans[name]
+		if !ok {
+			t.Errorf("no span %s in %v", name, spans)
+			continue
+		}
+		if span.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("span %s is not a child of the request", name)
+		}
+	}
+
+	rules, ok := spans["ValidationRules.Check"]
+	if !ok {
+		t.Fatalf("no span ValidationRules.Check in %v", spans)
+	}
+	if rules.Parent().SpanID() != spans["Questions.Update"].SpanContext().SpanID() {
+		t.Errorf("span ValidationRules.Check is not a child of Questions.Update")
+	}
+
+	// preloads are children of the statement loading the question
+	parents := map[trace.SpanID]bool{
+		spans["Questions.Update"].SpanContext().SpanID(): true,
+		rules.SpanContext().SpanID():                     true,
+	}
+	for _, statement := range statements {
+		parents[statement.SpanContext().SpanID()] = true
+	}
+	kinds := map[string]int{}
+	for _, statement := range statements {
+		if !parents[statement.Parent().SpanID()] {
+			t.Errorf("statement %s is not part of the usecase", statement.Name())
+		}
+		kinds[statement.Name()]++
+	}
+	if kinds["sql delete"] == 0 || kinds["sql create"] == 0 {
+		t.Errorf("statements traced %v, expected the delete and reinsert of the options", kinds)
+	}
+}
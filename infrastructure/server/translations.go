@@ -0,0 +1,116 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/togglhire/backend-homework/domain"
+	"golang.org/x/text/language"
+)
+
+func (s Server) handleTranslations(w http.ResponseWriter, r *http.Request, id int, segments []string) {
+	switch {
+	case len(segments) == 0 && r.Method == http.MethodGet:
+		s.listTranslations(w, r, id)
+	case len(segments) == 1 && segments[0] != "" && r.Method == http.MethodPut:
+		s.saveTranslation(w, r, id, segments[0])
+	case len(segments) == 1 && segments[0] != "" && r.Method == http.MethodDelete:
+		s.deleteTranslation(w, r, id, segments[0])
+	case len(segments) > 1:
+		writeProblem(w, r, http.StatusNotFound, "")
+	default:
+		writeProblem(w, r, http.StatusMethodNotAllowed, "invalid http method")
+	}
+}
+
+func (s Server) listTranslations(w http.ResponseWriter, r *http.Request, id int) {
+
+	translations, err := s.questions.GetTranslations(r.Context(), id)
+
+	if err != nil {
+		writeError(w, r, "Internal error listing translations", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(translations)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response list translations", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) saveTranslation(w http.ResponseWriter, r *http.Request, id int, locale string) {
+
+	var translation domain.Translation
+	if r.Body == nil {
+		writeProblem(w, r, http.StatusBadRequest, "Please send a request body")
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/json" {
+		writeProblem(w, r, http.StatusUnsupportedMediaType, "Incorrect media type")
+		return
+	}
+	err := json.NewDecoder(r.Body).Decode(&translation)
+	if err != nil {
+		writeBodyError(w, r, "failed to decode json body", err)
+		return
+	}
+
+	translation.Locale = locale
+	if err := validate.Struct(translation); err != nil {
+		writeInvalid(w, r, err)
+		return
+	}
+
+	translation, err = s.questions.SaveTranslation(r.Context(), id, translation)
+
+	if err != nil {
+		writeError(w, r, "Internal error saving translation", err)
+		return
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	err = json.NewEncoder(w).Encode(translation)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "err encoding json response save translation", "err", err)
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func (s Server) deleteTranslation(w http.ResponseWriter, r *http.Request, id int, locale string) {
+
+	err := s.questions.DeleteTranslation(r.Context(), id, locale)
+
+	if err != nil {
+		writeError(w, r, "Internal error deleting translation", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// acceptedLocales turns an Accept-Language header into the fallback chain of locales to try, by
+// preference and then walking up each tag parents, "es-MX" falls back to "es-419" and then "es".
+func acceptedLocales(header string) []string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	locales := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		for ; !tag.IsRoot(); tag = tag.Parent() {
+			if locale := tag.String(); !seen[locale] {
+				seen[locale] = true
+				locales = append(locales, locale)
+			}
+		}
+	}
+	return locales
+}
@@ -0,0 +1,195 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+)
+
+func buildTranslationJson(translation domain.Translation, t *testing.T) *bytes.Buffer {
+	input, err := json.Marshal(translation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewBuffer(input)
+}
+
+func TestServer_saveTranslation(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "Where does the sun set?", Options: []domain.Option{
+		{Body: "East"}, {Body: "West", Correct: true},
+	}})
+
+	valid := domain.Translation{Body: "¿Dónde se pone el sol?", Options: []domain.OptionTranslation{
+		{Body: "Este"}, {Body: "Oeste", Correct: true},
+	}}
+	fewerOptions := domain.Translation{Body: "¿Dónde se pone el sol?", Options: []domain.OptionTranslation{
+		{Body: "Este"},
+	}}
+	otherCorrect := domain.Translation{Body: "¿Dónde se pone el sol?", Options: []domain.OptionTranslation{
+		{Body: "Este", Correct: true}, {Body: "Oeste"},
+	}}
+
+	tests := []struct {
+		name           string
+		path           string
+		translation    domain.Translation
+		expectedStatus int
+	}{
+		{name: "valid translation should be OK", path: "/questions/1/translations/es", translation: valid, expectedStatus: http.StatusOK},
+		{name: "translation with different options count should fail with 400", path: "/questions/1/translations/es", translation: fewerOptions, expectedStatus: http.StatusBadRequest},
+		{name: "translation with different correct flags should fail with 400", path: "/questions/1/translations/es", translation: otherCorrect, expectedStatus: http.StatusBadRequest},
+		{name: "invalid locale should fail with 400", path: "/questions/1/translations/not_a_locale!", translation: valid, expectedStatus: http.StatusBadRequest},
+		{name: "non existent question should throw 404", path: "/questions/2/translations/es", translation: valid, expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPut, tt.path, buildTranslationJson(tt.translation, t))
+			r.Header.Add("Content-Type", "application/json")
+			srv.handleQuestion(rr, r)
+			if rr.Result().StatusCode != tt.expectedStatus {
+				t.Errorf("Status code returned, %d, did not match expected code %d", rr.Result().StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+
+	rr := httptest.NewRecorder()
+	srv.handleQuestion(rr, httptest.NewRequest(http.MethodGet, "/questions/1/translations", nil))
+	var translations []domain.Translation
+	if err := json.Unmarshal(rr.Body.Bytes(), &translations); err != nil {
+		t.Fatal(err)
+	}
+	valid.Locale = "es"
+	if !reflect.DeepEqual(translations, []domain.Translation{valid}) {
+		t.Errorf("translations returned, %+v, did not match expected %+v", translations, []domain.Translation{valid})
+	}
+}
+
+func TestServer_negotiateQuestionLanguage(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	question := domain.Question{ID: 1, Body: "Where does the sun set?", Options: []domain.Option{
+		{Body: "East"}, {Body: "West", Correct: true},
+	}}
+	_ = repo.Add(context.Background(), question)
+	_ = repo.SaveTranslation(context.Background(), 1, domain.Translation{Locale: "es", Body: "¿Dónde se pone el sol?", Options: []domain.OptionTranslation{
+		{Body: "Este"}, {Body: "Oeste", Correct: true},
+	}})
+
+	tests := []struct {
+		name             string
+		acceptLanguage   string
+		expectedBody     string
+		expectedLanguage string
+	}{
+		{name: "no header should return the source", acceptLanguage: "", expectedBody: "Where does the sun set?"},
+		{name: "exact locale should be translated", acceptLanguage: "es", expectedBody: "¿Dónde se pone el sol?", expectedLanguage: "es"},
+		{name: "regional locale should fall back to its language", acceptLanguage: "es-MX", expectedBody: "¿Dónde se pone el sol?", expectedLanguage: "es"},
+		{name: "preferred locale without translation should fall back to the next one", acceptLanguage: "de, es;q=0.5", expectedBody: "¿Dónde se pone el sol?", expectedLanguage: "es"},
+		{name: "unknown locales should return the source", acceptLanguage: "fr, de;q=0.8", expectedBody: "Where does the sun set?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/questions/1", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+			srv.handleQuestion(rr, r)
+
+			var got domain.Question
+			if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Body != tt.expectedBody {
+				t.Errorf("body returned, %s, did not match expected %s", got.Body, tt.expectedBody)
+			}
+			if lang := rr.Header().Get("Content-Language"); lang != tt.expectedLanguage {
+				t.Errorf("content language returned, %s, did not match expected %s", lang, tt.expectedLanguage)
+			}
+
+			rr = httptest.NewRecorder()
+			r = httptest.NewRequest(http.MethodGet, "/questions", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+			srv.listQuestions(rr, r)
+
+			var list []domain.Question
+			if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+				t.Fatal(err)
+			}
+			if len(list) != 1 || list[0].Body != tt.expectedBody {
+				t.Errorf("list returned, %+v, did not match expected body %s", list, tt.expectedBody)
+			}
+		})
+	}
+
+	question.Options = append(question.Options, domain.Option{Body: "North"})
+	if err := srv.questions.Update(context.Background(), question); err != nil {
+		t.Fatal(err)
+	}
+	translations, err := srv.questions.GetTranslations(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(translations) != 0 {
+		t.Errorf("translations not matching the updated question should be dropped, got %+v", translations)
+	}
+}
+
+func TestServer_translationErrors(t *testing.T) {
+	db := setupTestDB(t)
+	repo := sql.NewRepo(db)
+	srv := buildServer(repo, t)
+
+	_ = repo.Add(context.Background(), domain.Question{ID: 1, Body: "Where does the sun set?", Options: []domain.Option{
+		{Body: "East"}, {Body: "West", Correct: true},
+	}})
+	if err := db.Exec("DROP TABLE option_translation").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("DROP TABLE question_translation").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		path           string
+		acceptLanguage string
+		expectedStatus int
+	}{
+		{name: "get without locales should not read translations", handler: srv.handleQuestion, path: "/questions/1", expectedStatus: http.StatusOK},
+		{name: "list without locales should not read translations", handler: srv.handleQuestions, path: "/questions", expectedStatus: http.StatusOK},
+		{name: "get should fail", handler: srv.handleQuestion, path: "/questions/1", acceptLanguage: "es", expectedStatus: http.StatusInternalServerError},
+		{name: "list should fail", handler: srv.handleQuestions, path: "/questions", acceptLanguage: "es", expectedStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			rr := httptest.NewRecorder()
+			tt.handler(rr, r)
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("Status code returned, %d, did not match expected code %d: %s", rr.Code, tt.expectedStatus, rr.Body.String())
+			}
+			if tt.expectedStatus != http.StatusOK {
+				if got := rr.Header().Get("Content-Type"); got != PROBLEM_CONTENT_TYPE {
+					t.Errorf("content type returned, %s, did not match expected %s", got, PROBLEM_CONTENT_TYPE)
+				}
+			}
+		})
+	}
+}
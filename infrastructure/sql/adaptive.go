@@ -0,0 +1,214 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+)
+
+type ItemParameter struct {
+	OrganizationID string    `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int       `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Discrimination float64   `db:"discrimination"`
+	Difficulty     float64   `db:"difficulty"`
+	Responses      int       `db:"responses"`
+	CalibratedAt   time.Time `db:"calibrated_at"`
+}
+
+func (ItemParameter) TableName() string {
+	return "item_parameter"
+}
+
+type AdaptiveSession struct {
+	ID                     string                `db:"id"`
+	OrganizationID         string                `db:"organization_id"`
+	Candidate              string                `db:"candidate"`
+	Ability                float64               `db:"ability"`
+	StandardError          float64               `db:"standard_error"`
+	MaxItems               int                   `db:"max_items"`
+	StandardErrorThreshold float64               `db:"standard_error_threshold"`
+	NextQuestionID         *int                  `db:"next_question_id"`
+	Finished               bool                  `db:"finished"`
+	CreatedAt              time.Time             `db:"created_at"`
+	Items                  []AdaptiveSessionItem `gorm:"foreignKey:SessionID"`
+}
+
+func (AdaptiveSession) TableName() string {
+	return "adaptive_session"
+}
+
+type AdaptiveSessionItem struct {
+	SessionID  string `db:"session_id" gorm:"primaryKey"`
+	Position   int    `db:"position" gorm:"primaryKey;autoIncrement:false"`
+	QuestionID int    `db:"question_id"`
+	Correct    bool   `db:"correct"`
+}
+
+func (AdaptiveSessionItem) TableName() string {
+	return "adaptive_session_item"
+}
+
+func (r Repository) SaveItemParameters(parameters []domain.ItemParameters) error {
+	tx := r.db.Begin()
+
+	var owned []int
+	if err := tx.Model(&Question{}).Where("organization_id = ?", r.organization).Pluck("id", &owned).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err query questions of organization:%w", err)
+	}
+	ownedIDs := make(map[int]bool, len(owned))
+	for _, id := range owned {
+		ownedIDs[id] = true
+	}
+	for _, p := range parameters {
+		if !ownedIDs[p.QuestionID] {
+			_ = tx.Rollback()
+			return domain.ErrNoQuestionFound
+		}
+	}
+
+	if err := tx.Exec(`DELETE FROM item_parameter where organization_id = ?`, r.organization).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err sql exec deleting item parameters:%w", err)
+	}
+
+	if len(parameters) > 0 {
+		rows := make([]ItemParameter, 0, len(parameters))
+		for _, p := range parameters {
+			rows = append(rows, ItemParameter{OrganizationID: r.organization, QuestionID: p.QuestionID, Discrimination: p.Discrimination,
+				Difficulty: p.Difficulty, Responses: p.Responses, CalibratedAt: p.CalibratedAt})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("err sql exec adding item parameters:%w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx save item parameters:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) GetItemParameters() ([]domain.ItemParameters, error) {
+	var rows []ItemParameter
+	if err := r.db.Where("organization_id = ?", r.organization).Order("question_id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("err query get item parameters:%w", err)
+	}
+	parameters := make([]domain.ItemParameters, 0, len(rows))
+	for _, row := range rows {
+		parameters = append(parameters, domain.ItemParameters{QuestionID: row.QuestionID, Discrimination: row.Discrimination,
+			Difficulty: row.Difficulty, Responses: row.Responses, CalibratedAt: row.CalibratedAt})
+	}
+	return parameters, nil
+}
+
+func (r Repository) AddSession(session domain.AdaptiveSession) error {
+	dbSession := convertSessionToDBModel(session)
+	dbSession.OrganizationID = r.organization
+	if err := r.db.Create(&dbSession).Error; err != nil {
+		return fmt.Errorf("err sql exec adding adaptive session:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) GetSession(id string) (domain.AdaptiveSession, error) {
+	var rows []AdaptiveSession
+	if err := r.db.Preload("Items").Where("id = ? AND organization_id = ?", id, r.organization).Find(&rows).Error; err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err query get adaptive session:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.AdaptiveSession{}, domain.ErrNoSessionFound
+	}
+	return convertSessionToDomain(rows[0]), nil
+}
+
+func (r Repository) AnswerSession(session domain.AdaptiveSession, response domain.Response) (domain.Response, error) {
+	tx := r.db.Begin()
+
+	response, err := r.addResponse(tx, response)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Response{}, err
+	}
+	if err := r.updateSession(tx, session); err != nil {
+		_ = tx.Rollback()
+		return domain.Response{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return domain.Response{}, fmt.Errorf("err commit trx answer adaptive session:%w", err)
+	}
+	return response, nil
+}
+
+func (r Repository) updateSession(tx *gorm.DB, session domain.AdaptiveSession) error {
+	var owned int64
+	if err := tx.Model(&AdaptiveSession{}).Where("id = ? AND organization_id = ?", session.ID, r.organization).Count(&owned).Error; err != nil {
+		return fmt.Errorf("err query adaptive session exists:%w", err)
+	}
+	if owned == 0 {
+		return domain.ErrNoSessionFound
+	}
+
+	dbSession := convertSessionToDBModel(session)
+	err := tx.Model(&dbSession).Select("ability", "standard_error", "next_question_id", "finished").Updates(dbSession).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec updating adaptive session:%w", err)
+	}
+
+	var stored int64
+	if err := tx.Model(&AdaptiveSessionItem{}).Where("session_id = ?", session.ID).Count(&stored).Error; err != nil {
+		return fmt.Errorf("err query adaptive session items:%w", err)
+	}
+	if pending := dbSession.Items[stored:]; len(pending) > 0 {
+		if err := tx.Create(&pending).Error; err != nil {
+			return fmt.Errorf("err sql exec adding adaptive session items:%w", err)
+		}
+	}
+	return nil
+}
+
+func convertSessionToDBModel(session domain.AdaptiveSession) AdaptiveSession {
+	items := make([]AdaptiveSessionItem, 0, len(session.Items))
+	for i, item := range session.Items {
+		items = append(items, AdaptiveSessionItem{SessionID: session.ID, Position: i, QuestionID: item.QuestionID, Correct: item.Correct})
+	}
+	return AdaptiveSession{
+		ID:                     session.ID,
+		Candidate:              session.Candidate,
+		Ability:                session.Ability,
+		StandardError:          session.StandardError,
+		MaxItems:               session.MaxItems,
+		StandardErrorThreshold: session.StandardErrorThreshold,
+		NextQuestionID:         session.NextQuestionID,
+		Finished:               session.Finished,
+		CreatedAt:              session.CreatedAt,
+		Items:                  items,
+	}
+}
+
+func convertSessionToDomain(session AdaptiveSession) domain.AdaptiveSession {
+	sort.Slice(session.Items, func(i, j int) bool { return session.Items[i].Position < session.Items[j].Position })
+	items := make([]domain.AdaptiveItem, 0, len(session.Items))
+	for _, item := range session.Items {
+		items = append(items, domain.AdaptiveItem{QuestionID: item.QuestionID, Correct: item.Correct})
+	}
+	return domain.AdaptiveSession{
+		ID:                     session.ID,
+		Candidate:              session.Candidate,
+		Ability:                session.Ability,
+		StandardError:          session.StandardError,
+		MaxItems:               session.MaxItems,
+		StandardErrorThreshold: session.StandardErrorThreshold,
+		NextQuestionID:         session.NextQuestionID,
+		Finished:               session.Finished,
+		CreatedAt:              session.CreatedAt,
+		Items:                  items,
+	}
+}
@@ -0,0 +1,159 @@
+package sql
+
+import (
+	"fmt"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type QuestionStat struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int    `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Responses      int    `db:"responses"`
+	Correct        int    `db:"correct"`
+}
+
+func (QuestionStat) TableName() string {
+	return "question_stat"
+}
+
+type OptionStat struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int    `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Position       int    `db:"position" gorm:"primaryKey;autoIncrement:false"`
+	Picks          int    `db:"picks"`
+}
+
+func (OptionStat) TableName() string {
+	return "option_stat"
+}
+
+type CandidateScore struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	Candidate      string `db:"candidate" gorm:"primaryKey"`
+	Responses      int    `db:"responses"`
+	Correct        int    `db:"correct"`
+}
+
+func (CandidateScore) TableName() string {
+	return "candidate_score"
+}
+
+func (r Repository) GetItemCounts(questionIDs []int) ([]domain.ItemCounts, error) {
+	var questionStats []QuestionStat
+	query := r.db.Where("organization_id = ?", r.organization).Order("question_id")
+	if len(questionIDs) > 0 {
+		query = query.Where("question_id IN ?", questionIDs)
+	}
+	if err := query.Find(&questionStats).Error; err != nil {
+		return nil, fmt.Errorf("err query question stats:%w", err)
+	}
+
+	var optionStats []OptionStat
+	query = r.db.Model(&OptionStat{}).Where("organization_id = ?", r.organization)
+	if len(questionIDs) > 0 {
+		query = query.Where("question_id IN ?", questionIDs)
+	}
+	if err := query.Find(&optionStats).Error; err != nil {
+		return nil, fmt.Errorf("err query option stats:%w", err)
+	}
+
+	picks := make(map[int]map[int]int)
+	for _, stat := range optionStats {
+		if picks[stat.QuestionID] == nil {
+			picks[stat.QuestionID] = make(map[int]int)
+		}
+		picks[stat.QuestionID][stat.Position] = stat.Picks
+	}
+
+	counts := make([]domain.ItemCounts, 0, len(questionStats))
+	for _, stat := range questionStats {
+		counts = append(counts, domain.ItemCounts{QuestionID: stat.QuestionID, Responses: stat.Responses, Correct: stat.Correct, Picks: picks[stat.QuestionID]})
+	}
+	return counts, nil
+}
+
+func (r Repository) GetItemScores(questionIDs []int) ([]domain.ItemScore, error) {
+	var scores []domain.ItemScore
+	query := r.db.Table("response").
+		Select("response.question_id, response.correct, candidate_score.responses AS candidate_responses, candidate_score.correct AS candidate_correct").
+		Joins("JOIN candidate_score ON candidate_score.candidate = response.candidate AND candidate_score.organization_id = ?", r.organization).
+		Where("response.organization_id = ?", r.organization).
+		Order("response.id")
+	if len(questionIDs) > 0 {
+		query = query.Where("response.question_id IN ?", questionIDs)
+	}
+	if err := query.Scan(&scores).Error; err != nil {
+		return nil, fmt.Errorf("err query item scores:%w", err)
+	}
+	return scores, nil
+}
+
+// recordResponseStats keeps the running totals up to date, in the same transaction the response is stored.
+func recordResponseStats(tx *gorm.DB, response Response, organization string) error {
+	correct := 0
+	if response.Correct {
+		correct = 1
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "question_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"responses": gorm.Expr("question_stat.responses + 1"),
+			"correct":   gorm.Expr("question_stat.correct + ?", correct),
+		}),
+	}).Create(&QuestionStat{OrganizationID: organization, QuestionID: response.QuestionID, Responses: 1, Correct: correct}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec recording question stats:%w", err)
+	}
+
+	for _, opt := range response.Options {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "question_id"}, {Name: "position"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"picks": gorm.Expr("option_stat.picks + 1")}),
+		}).Create(&OptionStat{OrganizationID: organization, QuestionID: response.QuestionID, Position: opt.Position, Picks: 1}).Error
+		if err != nil {
+			return fmt.Errorf("err sql exec recording option stats:%w", err)
+		}
+	}
+
+	err = tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "organization_id"}, {Name: "candidate"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"responses": gorm.Expr("candidate_score.responses + 1"),
+			"correct":   gorm.Expr("candidate_score.correct + ?", correct),
+		}),
+	}).Create(&CandidateScore{OrganizationID: organization, Candidate: response.Candidate, Responses: 1, Correct: correct}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec recording candidate score:%w", err)
+	}
+	return nil
+}
+
+// forgetQuestionStats drops the totals of a question and takes its responses out of the candidate scores.
+func forgetQuestionStats(tx *gorm.DB, questionID int, organization string) error {
+	var responses []Response
+	if err := tx.Where("organization_id = ? AND question_id = ?", organization, questionID).Find(&responses).Error; err != nil {
+		return fmt.Errorf("err query responses of question:%w", err)
+	}
+	for _, response := range responses {
+		correct := 0
+		if response.Correct {
+			correct = 1
+		}
+		err := tx.Exec(`UPDATE candidate_score SET responses = responses - 1, correct = correct - ? where organization_id = ? AND candidate = ?`, correct, organization, response.Candidate).Error
+		if err != nil {
+			return fmt.Errorf("err sql exec updating candidate score:%w", err)
+		}
+	}
+
+	if err := tx.Exec(`DELETE FROM option_stat where organization_id = ? AND question_id = ?`, organization, questionID).Error; err != nil {
+		return fmt.Errorf("err sql exec deleting option stats:%w", err)
+	}
+	if err := tx.Exec(`DELETE FROM question_stat where organization_id = ? AND question_id = ?`, organization, questionID).Error; err != nil {
+		return fmt.Errorf("err sql exec deleting question stats:%w", err)
+	}
+	return nil
+}
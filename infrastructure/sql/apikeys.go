@@ -0,0 +1,144 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+type APIKey struct {
+	ID             string        `db:"id"`
+	OrganizationID string        `db:"organization_id"`
+	Name           string        `db:"name"`
+	Prefix         string        `db:"prefix"`
+	Hash           string        `db:"hash"`
+	CreatedAt      time.Time     `db:"created_at"`
+	LastUsedAt     *time.Time    `db:"last_used_at"`
+	ExpiresAt      *time.Time    `db:"expires_at"`
+	RevokedAt      *time.Time    `db:"revoked_at"`
+	Scopes         []APIKeyScope `gorm:"foreignKey:APIKeyID"`
+}
+
+func (APIKey) TableName() string {
+	return "api_key"
+}
+
+type APIKeyScope struct {
+	APIKeyID string `db:"api_key_id" gorm:"primaryKey"`
+	Scope    string `db:"scope" gorm:"primaryKey"`
+}
+
+func (APIKeyScope) TableName() string {
+	return "api_key_scope"
+}
+
+func (r Repository) AddAPIKey(key domain.APIKey, hash string) error {
+	dbKey := convertAPIKeyToDBModel(key)
+	dbKey.OrganizationID = r.organization
+	dbKey.Hash = hash
+	if err := r.db.Create(&dbKey).Error; err != nil {
+		return fmt.Errorf("err sql exec adding api key:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) GetAPIKeys() ([]domain.APIKey, error) {
+	var rows []APIKey
+	err := r.db.Preload("Scopes").Where("organization_id = ?", r.organization).Order("created_at, id").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("err query get api keys:%w", err)
+	}
+	keys := make([]domain.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, convertAPIKeyToDomain(row))
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey keeps the first revocation time, revoking twice is not an error.
+func (r Repository) RevokeAPIKey(id string, at time.Time) error {
+	tx := r.db.Begin()
+
+	var count int64
+	if err := tx.Model(&APIKey{}).Where("id = ? AND organization_id = ?", id, r.organization).Count(&count).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err query api key exists:%w", err)
+	}
+	if count == 0 {
+		_ = tx.Rollback()
+		return domain.ErrNoAPIKeyFound
+	}
+
+	err := tx.Exec(`UPDATE api_key SET revoked_at = ? where id = ? AND revoked_at IS NULL`, at, id).Error
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err sql exec revoking api key:%w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx revoke api key:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) FindAPIKey(hash string) (domain.APIKey, error) {
+	var rows []APIKey
+	if err := r.db.Preload("Scopes").Where("hash = ?", hash).Find(&rows).Error; err != nil {
+		return domain.APIKey{}, fmt.Errorf("err query find api key:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.APIKey{}, domain.ErrNoAPIKeyFound
+	}
+	return convertAPIKeyToDomain(rows[0]), nil
+}
+
+func (r Repository) TouchAPIKey(id string, at time.Time) error {
+	if err := r.db.Exec(`UPDATE api_key SET last_used_at = ? where id = ?`, at, id).Error; err != nil {
+		return fmt.Errorf("err sql exec touching api key:%w", err)
+	}
+	return nil
+}
+
+func convertAPIKeyToDBModel(key domain.APIKey) APIKey {
+	scopes := make([]APIKeyScope, 0, len(key.Scopes))
+	seen := make(map[string]bool, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, APIKeyScope{APIKeyID: key.ID, Scope: scope})
+		}
+	}
+	return APIKey{
+		ID:             key.ID,
+		OrganizationID: key.Organization,
+		Name:           key.Name,
+		Prefix:         key.Prefix,
+		CreatedAt:      key.CreatedAt,
+		LastUsedAt:     key.LastUsedAt,
+		ExpiresAt:      key.ExpiresAt,
+		RevokedAt:      key.RevokedAt,
+		Scopes:         scopes,
+	}
+}
+
+func convertAPIKeyToDomain(key APIKey) domain.APIKey {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.Scope)
+	}
+	sort.Strings(scopes)
+	return domain.APIKey{
+		ID:           key.ID,
+		Organization: key.OrganizationID,
+		Name:         key.Name,
+		Prefix:       key.Prefix,
+		Scopes:       scopes,
+		CreatedAt:    key.CreatedAt,
+		LastUsedAt:   key.LastUsedAt,
+		ExpiresAt:    key.ExpiresAt,
+		RevokedAt:    key.RevokedAt,
+	}
+}
@@ -0,0 +1,169 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+)
+
+type Attachment struct {
+	ID             string    `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	Filename       string    `db:"filename"`
+	ContentType    string    `db:"content_type"`
+	Size           int64     `db:"size"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func (Attachment) TableName() string {
+	return "attachment"
+}
+
+type QuestionAttachment struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int    `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	AttachmentID   string `db:"attachment_id"`
+	Position       int    `db:"position" gorm:"primaryKey"`
+}
+
+func (QuestionAttachment) TableName() string {
+	return "question_attachment"
+}
+
+type OptionAttachment struct {
+	OptionID     int    `db:"option_id" gorm:"primaryKey"`
+	AttachmentID string `db:"attachment_id"`
+	Position     int    `db:"position" gorm:"primaryKey"`
+}
+
+func (OptionAttachment) TableName() string {
+	return "option_attachment"
+}
+
+func (r Repository) AddAttachment(attachment domain.Attachment) error {
+	dbAttachment := Attachment{ID: attachment.ID, OrganizationID: r.organization, Filename: attachment.Filename,
+		ContentType: attachment.ContentType, Size: attachment.Size, CreatedAt: attachment.CreatedAt}
+	if err := r.db.Create(&dbAttachment).Error; err != nil {
+		return fmt.Errorf("err sql exec adding attachment:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) GetAttachment(id string) (domain.Attachment, error) {
+	var rows []Attachment
+	if err := r.db.Where("id = ? AND organization_id = ?", id, r.organization).Find(&rows).Error; err != nil {
+		return domain.Attachment{}, fmt.Errorf("err query get attachment:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.Attachment{}, domain.ErrNoAttachmentFound
+	}
+	return convertAttachmentToDomain(rows[0]), nil
+}
+
+func (r Repository) DeleteUnreferencedAttachments(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.deleteUnreferencedAttachments(func(db *gorm.DB) *gorm.DB { return db.Where("id IN ?", ids) })
+}
+
+func (r Repository) DeleteStaleAttachments(cutoff time.Time) ([]string, error) {
+	return r.deleteUnreferencedAttachments(func(db *gorm.DB) *gorm.DB { return db.Where("created_at < ?", cutoff) })
+}
+
+// deleteUnreferencedAttachments removes the attachments of the organization picked by candidates that nothing
+// points to.
+func (r Repository) deleteUnreferencedAttachments(candidates func(*gorm.DB) *gorm.DB) ([]string, error) {
+	tx := r.db.Begin()
+
+	var orphans []string
+	err := tx.Model(&Attachment{}).Scopes(candidates).
+		Where("organization_id = ?", r.organization).
+		Where("id NOT IN (?)", tx.Model(&QuestionAttachment{}).Select("attachment_id")).
+		Where("id NOT IN (?)", tx.Model(&OptionAttachment{}).Select("attachment_id")).
+		Pluck("id", &orphans).Error
+	if err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("err query unreferenced attachments:%w", err)
+	}
+
+	if len(orphans) > 0 {
+		if err := tx.Where("id IN ?", orphans).Delete(&Attachment{}).Error; err != nil {
+			_ = tx.Rollback()
+			return nil, fmt.Errorf("err sql exec deleting attachments:%w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return nil, fmt.Errorf("err commit trx delete attachments:%w", err)
+	}
+	return orphans, nil
+}
+
+func (r Repository) checkAttachmentsExist(tx *gorm.DB, ids []string) error {
+	unique := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(&Attachment{}).Where("id IN ? AND organization_id = ?", ids, r.organization).Count(&count).Error; err != nil {
+		return fmt.Errorf("err query attachments exist:%w", err)
+	}
+	if int(count) != len(unique) {
+		return domain.ErrNoAttachmentFound
+	}
+	return nil
+}
+
+func convertAttachmentToDomain(attachment Attachment) domain.Attachment {
+	return domain.Attachment{ID: attachment.ID, Filename: attachment.Filename, ContentType: attachment.ContentType,
+		Size: attachment.Size, CreatedAt: attachment.CreatedAt}
+}
+
+func questionAttachmentsToDBModel(questionID int, organization string, ids []string) []QuestionAttachment {
+	attachments := make([]QuestionAttachment, 0, len(ids))
+	for i, id := range ids {
+		attachments = append(attachments, QuestionAttachment{OrganizationID: organization, QuestionID: questionID, AttachmentID: id, Position: i})
+	}
+	return attachments
+}
+
+func optionAttachmentsToDBModel(ids []string) []OptionAttachment {
+	attachments := make([]OptionAttachment, 0, len(ids))
+	for i, id := range ids {
+		attachments = append(attachments, OptionAttachment{AttachmentID: id, Position: i})
+	}
+	return attachments
+}
+
+func questionAttachmentIDs(attachments []QuestionAttachment) []string {
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].Position < attachments[j].Position })
+	ids := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		ids = append(ids, a.AttachmentID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
+
+func optionAttachmentIDs(attachments []OptionAttachment) []string {
+	sort.Slice(attachments, func(i, j int) bool { return attachments[i].Position < attachments[j].Position })
+	ids := make([]string, 0, len(attachments))
+	for _, a := range attachments {
+		ids = append(ids, a.AttachmentID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return ids
+}
@@ -0,0 +1,191 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+)
+
+type AuditLog struct {
+	ID             int64     `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	QuestionID     int       `db:"question_id"`
+	Action         string    `db:"action"`
+	Actor          string    `db:"actor"`
+	RequestID      string    `db:"request_id"`
+	IP             string    `db:"ip"`
+	Before         *string   `db:"before"`
+	After          *string   `db:"after"`
+	CreatedAt      time.Time `db:"created_at"`
+	PrevHash       string    `db:"prev_hash"`
+	Hash           string    `db:"hash"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+// WithActor returns a copy of the repository recording its changes of questions as made by actor.
+func (r Repository) WithActor(actor domain.Actor) Repository {
+	r.actor = actor
+	return r
+}
+
+func (r Repository) GetAuditEntries(ctx context.Context, filter domain.AuditFilter) (_ []domain.AuditEntry, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	query := db.Where("organization_id = ?", r.organization)
+	if filter.QuestionID != nil {
+		query = query.Where("question_id = ?", *filter.QuestionID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", string(filter.Action))
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", filter.From.UTC())
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", filter.To.UTC())
+	}
+	if filter.Cursor > 0 {
+		query = query.Where("id < ?", filter.Cursor)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var rows []AuditLog
+	if err := query.Order("id DESC").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("err query get audit entries:%w", err)
+	}
+	return convertAuditToDomain(rows), nil
+}
+
+func (r Repository) GetAuditChain(ctx context.Context) (_ []domain.AuditEntry, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	var rows []AuditLog
+	if err := db.Where("organization_id = ?", r.organization).Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("err query get audit chain:%w", err)
+	}
+	return convertAuditToDomain(rows), nil
+}
+
+// recordAudit appends an entry to the chain of the organization, in the transaction of the change itself. Before
+// and after are what changed, the question or one of its translations, nil when there is none.
+func (r Repository) recordAudit(tx *gorm.DB, action domain.AuditAction, questionID int, before, after interface{}) error {
+	if err := r.lockAuditChain(tx); err != nil {
+		return err
+	}
+	var last []AuditLog
+	if err := tx.Where("organization_id = ?", r.organization).Order("id DESC").Limit(1).Find(&last).Error; err != nil {
+		return fmt.Errorf("err query last audit entry:%w", err)
+	}
+
+	entry := domain.AuditEntry{
+		QuestionID: questionID,
+		Action:     action,
+		Actor:      r.actor.Subject,
+		RequestID:  r.actor.RequestID,
+		IP:         r.actor.IP,
+		// PostgreSQL keeps microseconds, the hash must cover what is read back
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	if len(last) > 0 {
+		entry.PrevHash = last[0].Hash
+	}
+	var err error
+	if entry.Before, err = marshalAudit(before); err != nil {
+		return err
+	}
+	if entry.After, err = marshalAudit(after); err != nil {
+		return err
+	}
+	entry.Hash = entry.ComputeHash()
+
+	row := AuditLog{
+		OrganizationID: r.organization,
+		QuestionID:     entry.QuestionID,
+		Action:         string(entry.Action),
+		Actor:          entry.Actor,
+		RequestID:      entry.RequestID,
+		IP:             entry.IP,
+		Before:         rawToNullable(entry.Before),
+		After:          rawToNullable(entry.After),
+		CreatedAt:      entry.CreatedAt,
+		PrevHash:       entry.PrevHash,
+		Hash:           entry.Hash,
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return fmt.Errorf("err sql exec adding audit entry:%w", err)
+	}
+	return nil
+}
+
+// lockAuditChain keeps other transactions from appending to the chain of the organization until tx ends, two
+// reading the same last entry would fork the chain. SQLite needs no lock, it lets one transaction write at a
+// time and every change writes its question before the entry.
+func (r Repository) lockAuditChain(tx *gorm.DB) error {
+	if tx.Dialector.Name() != DialectPostgres {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "audit_log:"+r.organization).Error; err != nil {
+		return fmt.Errorf("err locking audit chain:%w", err)
+	}
+	return nil
+}
+
+func marshalAudit(changed interface{}) (json.RawMessage, error) {
+	if changed == nil {
+		return nil, nil
+	}
+	raw, err := json.Marshal(changed)
+	if err != nil {
+		return nil, fmt.Errorf("err marshalling change for audit:%w", err)
+	}
+	return raw, nil
+}
+
+func rawToNullable(raw json.RawMessage) *string {
+	if raw == nil {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
+
+func convertAuditToDomain(rows []AuditLog) []domain.AuditEntry {
+	entries := make([]domain.AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := domain.AuditEntry{
+			ID:         row.ID,
+			QuestionID: row.QuestionID,
+			Action:     domain.AuditAction(row.Action),
+			Actor:      row.Actor,
+			RequestID:  row.RequestID,
+			IP:         row.IP,
+			CreatedAt:  row.CreatedAt,
+			PrevHash:   row.PrevHash,
+			Hash:       row.Hash,
+		}
+		if row.Before != nil {
+			entry.Before = json.RawMessage(*row.Before)
+		}
+		if row.After != nil {
+			entry.After = json.RawMessage(*row.After)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
@@ -0,0 +1,49 @@
+package sql_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
+	"github.com/togglhire/backend-homework/usecase"
+)
+
+func TestRepository_auditChainConcurrentWriters(t *testing.T) {
+	db := sqltest.Open(t)
+	if db.Dialector.Name() != sql.DialectPostgres {
+		t.Skip("SQLite lets one transaction write at a time, set TEST_DATABASE_URL to run it on PostgreSQL")
+	}
+	repo := sql.NewRepo(db)
+	ctx := context.Background()
+
+	const writers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for i := 1; i <= writers; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			errs <- repo.Add(ctx, domain.Question{ID: id, Body: "question", Options: []domain.Option{
+				{Body: "yes", Correct: true}, {Body: "no"},
+			}})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	verification, err := usecase.NewAudit(repo).Verify(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verification.Valid || verification.Entries != writers {
+		t.Errorf("audit chain of concurrent writers is %+v, expected %d valid entries", verification, writers)
+	}
+}
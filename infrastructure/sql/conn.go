@@ -1,15 +1,21 @@
 package sql
 
 import (
+	"database/sql"
 	"embed"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source/httpfs"
+	gormpostgres "gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -18,54 +24,94 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// migrations holds one set of migrations per dialect, in migrations/<dialect>.
+//
 //go:embed migrations
 var migrations embed.FS
 
-func SetupSQLConnection(databaseURL string) *gorm.DB {
-	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags), // io writer
-		logger.Config{
-			LogLevel: logger.Silent, // Log level
-		},
-	)
-	g, err := gorm.Open(sqlite.Open(databaseURL), &gorm.Config{Logger: newLogger})
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+// Dialect picks the backend of a database url by its scheme, postgres:// and postgresql:// are PostgreSQL,
+// sqlite:// or no scheme at all is the path of a SQLite file.
+func Dialect(databaseURL string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(databaseURL, "postgres://"), strings.HasPrefix(databaseURL, "postgresql://"):
+		return DialectPostgres, databaseURL, nil
+	case strings.HasPrefix(databaseURL, "sqlite://"):
+		return DialectSQLite, strings.TrimPrefix(databaseURL, "sqlite://"), nil
+	case strings.Contains(databaseURL, "://"):
+		return "", "", fmt.Errorf("err unsupported database url scheme in %q, expected sqlite:// or postgres://", strings.SplitN(databaseURL, "://", 2)[0]+"://")
+	}
+	return DialectSQLite, databaseURL, nil
+}
+
+// SetupSQLConnection opens and migrates the database, gorm logs to log. The process exits when it can not.
+func SetupSQLConnection(databaseURL string, log logger.Interface) *gorm.DB {
+	dialect, dsn, err := Dialect(databaseURL)
+	if err != nil {
+		fatal("err parsing database url", err)
+	}
+
+	dialector := sqlite.Open(dsn)
+	if dialect == DialectPostgres {
+		dialector = gormpostgres.Open(dsn)
+	}
+	g, err := gorm.Open(dialector, &gorm.Config{Logger: log})
 	if err != nil {
-		log.Fatal(err)
+		fatal("err opening database", err)
 	}
 
 	db, err := g.DB()
 	if err != nil {
-		log.Fatal(err)
+		fatal("err getting connection pool", err)
 	}
 
+	// gorm warns of every callback removed, these are on purpose
+	g.Logger = logger.Discard
 	_ = g.Callback().Create().Remove("gorm:update_time_stamp")
 	_ = g.Callback().Update().Remove("gorm:update_time_stamp")
 	_ = g.Callback().Delete().Remove("gorm:update_time_stamp")
+	g.Logger = log
 
-	driver, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	driver, err := migrationDriver(dialect, db)
 	if err != nil {
-		log.Fatalln(err)
+		fatal("err preparing migration driver", err)
 	}
 
-	sourceInstance, err := httpfs.New(http.FS(migrations), "migrations")
+	sourceInstance, err := httpfs.New(http.FS(migrations), "migrations/"+dialect)
 	if err != nil {
-		log.Fatal(err)
+		fatal("err reading migrations", err)
 	}
 
 	m, err := migrate.NewWithInstance(
-		"httpfs", sourceInstance, "sqlite", driver)
+		"httpfs", sourceInstance, dialect, driver)
 
 	if err != nil {
-		log.Fatalln("err preparing migrations", err)
+		fatal("err preparing migrations", err)
 	}
 	err = m.Up()
 	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
-		log.Fatalln("err executing migrations", err)
+		fatal("err executing migrations", err)
 	}
-	log.Println("sql: all migrations run successfully")
+	slog.Info("sql: all migrations run successfully", "dialect", dialect)
 
 	if err := db.Ping(); err != nil {
-		log.Fatalln("err pinging conn", err)
+		fatal("err pinging conn", err)
 	}
 	return g
 }
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
+}
+
+func migrationDriver(dialect string, db *sql.DB) (database.Driver, error) {
+	if dialect == DialectPostgres {
+		return postgres.WithInstance(db, &postgres.Config{})
+	}
+	return sqlite3.WithInstance(db, &sqlite3.Config{})
+}
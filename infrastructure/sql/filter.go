@@ -0,0 +1,76 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// filterColumns is what each filterable field is compared as. Only these expressions and the operators below
+// ever reach the query, values are always bound as parameters.
+var filterColumns = map[string]string{
+	"id":                   "id",
+	"body":                 "body",
+	"explanation":          "explanation",
+	"difficulty":           "difficulty",
+	"points":               "points",
+	"estimated_seconds":    "estimated_seconds",
+	"updated_at":           "updated_at",
+	"options_count":        `(SELECT COUNT(*) FROM "option" WHERE ` + optionsOfQuestion + `)`,
+	"correct_count":        `(SELECT COUNT(*) FROM "option" WHERE ` + optionsOfQuestion + ` AND "option".correct)`,
+	"has_multiple_correct": `((SELECT COUNT(*) FROM "option" WHERE ` + optionsOfQuestion + ` AND "option".correct) > 1)`,
+}
+
+// optionsOfQuestion correlates the options subqueries with the question filtered.
+const optionsOfQuestion = `"option".organization_id = question.organization_id AND "option".question_id = question.id`
+
+var filterOperators = map[domain.FilterOperator]string{
+	domain.FilterEqual:          "=",
+	domain.FilterNotEqual:       "<>",
+	domain.FilterLess:           "<",
+	domain.FilterLessOrEqual:    "<=",
+	domain.FilterGreater:        ">",
+	domain.FilterGreaterOrEqual: ">=",
+}
+
+// conditionSQL turns a condition into a where clause and its arguments.
+func conditionSQL(condition domain.Condition) (string, []interface{}, error) {
+	kind, ok := domain.FilterableQuestionFields[condition.Field]
+	if !ok || !kind.Allows(condition.Operator) {
+		return "", nil, fmt.Errorf("err filtering questions by %s %s", condition.Field, condition.Operator)
+	}
+
+	value := condition.Value
+	switch v := value.(type) {
+	case domain.Difficulty:
+		value = v.Rank()
+	case time.Time:
+		value = v.UTC()
+	}
+
+	if condition.Field == "tag" {
+		tags := "SELECT question_id FROM question_tag WHERE question_tag.organization_id = question.organization_id AND tag = ?"
+		if condition.Operator == domain.FilterContains {
+			tags = `SELECT question_id FROM question_tag WHERE question_tag.organization_id = question.organization_id AND LOWER(tag) LIKE ? ESCAPE '\'`
+			value = containsPattern(value.(string))
+		}
+		if condition.Operator == domain.FilterNotEqual {
+			return "id NOT IN (" + tags + ")", []interface{}{value}, nil
+		}
+		return "id IN (" + tags + ")", []interface{}{value}, nil
+	}
+
+	column := filterColumns[condition.Field]
+	if condition.Operator == domain.FilterContains {
+		return "LOWER(" + column + `) LIKE ? ESCAPE '\'`, []interface{}{containsPattern(value.(string))}, nil
+	}
+	return column + " " + filterOperators[condition.Operator] + " ?", []interface{}{value}, nil
+}
+
+// containsPattern matches text containing value, the wildcards of LIKE in value match themselves.
+func containsPattern(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(value))
+	return "%" + escaped + "%"
+}
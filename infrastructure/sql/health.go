@@ -0,0 +1,97 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Health checks the database is fit to serve requests.
+type Health struct {
+	db *gorm.DB
+}
+
+func NewHealth(db *gorm.DB) Health {
+	return Health{db: db}
+}
+
+// Ping tells if a connection to the database can be made.
+func (h Health) Ping(ctx context.Context) error {
+	conn, err := h.db.DB()
+	if err != nil {
+		return fmt.Errorf("err getting connection pool:%w", err)
+	}
+	if err := conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("err pinging database:%w", err)
+	}
+	return nil
+}
+
+// Migrations tells if every migration this build knows of ran, and none was left halfway.
+func (h Health) Migrations(ctx context.Context) error {
+	expected, err := latestMigration(h.db.Dialector.Name())
+	if err != nil {
+		return err
+	}
+
+	var current struct {
+		Version uint
+		Dirty   bool
+	}
+	err = h.db.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&current).Error
+	if err != nil {
+		return fmt.Errorf("err reading migration version:%w", err)
+	}
+	if current.Dirty {
+		return fmt.Errorf("migration %d failed halfway and needs fixing by hand", current.Version)
+	}
+	if current.Version != expected {
+		return fmt.Errorf("database is at migration %d, expected %d", current.Version, expected)
+	}
+	return nil
+}
+
+// Writable tells if the database accepts writes, a full disk or a read only file or replica does not. Nothing is
+// changed, the write is rolled back.
+func (h Health) Writable(ctx context.Context) error {
+	tx := h.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("err beginning write check:%w", tx.Error)
+	}
+	defer tx.Rollback()
+
+	if err := tx.Exec("UPDATE schema_migrations SET dirty = dirty").Error; err != nil {
+		return fmt.Errorf("err writing to database:%w", err)
+	}
+	return nil
+}
+
+// latestMigration is the version of the last migration embedded for the dialect.
+func latestMigration(dialect string) (uint, error) {
+	entries, err := fs.ReadDir(migrations, "migrations/"+dialect)
+	if err != nil {
+		return 0, fmt.Errorf("err reading migrations:%w", err)
+	}
+	var latest uint
+	for _, entry := range entries {
+		prefix, _, found := strings.Cut(entry.Name(), "_")
+		if !found || !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("err parsing version of migration %s:%w", entry.Name(), err)
+		}
+		if uint(version) > latest {
+			latest = uint(version)
+		}
+	}
+	if latest == 0 {
+		return 0, fmt.Errorf("err no migrations embedded for %s", dialect)
+	}
+	return latest, nil
+}
@@ -0,0 +1,62 @@
+package sql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
+)
+
+func TestHealth(t *testing.T) {
+	ctx := context.Background()
+	db := sqltest.Open(t)
+	health := sql.NewHealth(db)
+
+	for name, check := range map[string]func(context.Context) error{
+		"ping": health.Ping, "migrations": health.Migrations, "writable": health.Writable,
+	} {
+		if err := check(ctx); err != nil {
+			t.Errorf("%s of a migrated database failed: %s", name, err)
+		}
+	}
+
+	if err := db.Exec("UPDATE schema_migrations SET version = version - 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := health.Migrations(ctx); err == nil || !strings.Contains(err.Error(), "expected") {
+		t.Errorf("migrations of a database behind returned %v, expected it to be behind", err)
+	}
+
+	if err := db.Exec("UPDATE schema_migrations SET version = version + 1, dirty = true").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := health.Migrations(ctx); err == nil || !strings.Contains(err.Error(), "halfway") {
+		t.Errorf("migrations of a dirty database returned %v, expected it to be dirty", err)
+	}
+}
+
+func TestHealth_readOnly(t *testing.T) {
+	db := sqltest.Open(t)
+	if db.Dialector.Name() != sql.DialectSQLite {
+		t.Skip("query_only is a SQLite pragma")
+	}
+	conn, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the pragma holds for one connection, keep to it
+	conn.SetMaxOpenConns(1)
+	if err := db.Exec("PRAGMA query_only = ON").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	health := sql.NewHealth(db)
+	if err := health.Writable(context.Background()); err == nil {
+		t.Errorf("a read only database is writable")
+	}
+	if err := health.Ping(context.Background()); err != nil {
+		t.Errorf("ping of a read only database failed: %s", err)
+	}
+}
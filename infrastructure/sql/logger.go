@@ -0,0 +1,70 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Logger hands what gorm logs to slog. Statements are debug records, those taking longer than the slow
+// threshold are warnings and failing ones errors, records not found are not failures. A zero threshold
+// never calls a statement slow.
+type Logger struct {
+	logger        *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewLogger(l *slog.Logger, slowThreshold time.Duration) Logger {
+	return Logger{logger: l, level: logger.Info, slowThreshold: slowThreshold}
+}
+
+// LogMode only silences gorm or keeps the levels above the one given, slog filters the rest.
+func (l Logger) LogMode(level logger.LogLevel) logger.Interface {
+	l.level = level
+	return l
+}
+
+func (l Logger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l Logger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l Logger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= logger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= logger.Error:
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "sql statement failed", slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("duration", elapsed), slog.String("err", err.Error()))
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "slow sql statement", slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("duration", elapsed), slog.Duration("threshold", l.slowThreshold))
+	case l.level >= logger.Info && l.logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		l.logger.DebugContext(ctx, "sql statement", slog.String("sql", sql), slog.Int64("rows", rows),
+			slog.Duration("duration", elapsed))
+	}
+}
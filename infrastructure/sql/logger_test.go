@@ -0,0 +1,48 @@
+package sql_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLogger_Trace(t *testing.T) {
+	statement := func() (string, int64) { return "SELECT 1", 1 }
+	tests := []struct {
+		name     string
+		level    slog.Level
+		mode     logger.LogLevel
+		elapsed  time.Duration
+		err      error
+		expected string
+	}{
+		{name: "failed", level: slog.LevelInfo, mode: logger.Info, err: errors.New("no such table"), expected: `level=ERROR msg="sql statement failed" sql="SELECT 1"`},
+		{name: "not found is no failure", level: slog.LevelInfo, mode: logger.Info, err: gorm.ErrRecordNotFound, expected: ""},
+		{name: "slow", level: slog.LevelInfo, mode: logger.Info, elapsed: time.Second, expected: `level=WARN msg="slow sql statement" sql="SELECT 1"`},
+		{name: "fast at debug", level: slog.LevelDebug, mode: logger.Info, expected: `level=DEBUG msg="sql statement" sql="SELECT 1"`},
+		{name: "fast at info", level: slog.LevelInfo, mode: logger.Info, expected: ""},
+		{name: "silenced", level: slog.LevelDebug, mode: logger.Silent, err: errors.New("no such table"), expected: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := sql.NewLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: tt.level})), 100*time.Millisecond).LogMode(tt.mode)
+
+			l.Trace(context.Background(), time.Now().Add(-tt.elapsed), statement, tt.err)
+			if tt.expected == "" && buf.Len() > 0 {
+				t.Errorf("logged %s, expected nothing", buf.String())
+			}
+			if !strings.Contains(buf.String(), tt.expected) {
+				t.Errorf("logged %s, expected %s", buf.String(), tt.expected)
+			}
+		})
+	}
+}
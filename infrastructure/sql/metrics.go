@@ -0,0 +1,132 @@
+package sql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+// metrics are collected under this name, it is the db_name label of the pool statistics too
+const METRICS_DB_NAME = "questions"
+
+// counting the library runs on every scrape, it gives up rather than holding the scrape
+const METRICS_LIBRARY_TIMEOUT = 5 * time.Second
+
+const metricsStartKey = "metrics:start"
+
+// RegisterMetrics times every query gorm runs on db, counts those failing, and exposes the statistics of the
+// connection pool and the size of the library of every organization.
+func RegisterMetrics(db *gorm.DB, registry prometheus.Registerer) error {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "db_query_duration_seconds",
+		Help:    "Time taken by database queries, by operation and table.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation", "table"})
+	failures := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "db_query_errors_total",
+		Help: "Database queries that failed, by operation and table. Records not found are not failures.",
+	}, []string{"operation", "table"})
+
+	conn, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("err getting connection pool:%w", err)
+	}
+	for _, collector := range []prometheus.Collector{
+		duration, failures, collectors.NewDBStatsCollector(conn, METRICS_DB_NAME), libraryCollector{db: db},
+	} {
+		if err := registry.Register(collector); err != nil {
+			return fmt.Errorf("err registering database metrics:%w", err)
+		}
+	}
+
+	start := func(tx *gorm.DB) {
+		tx.InstanceSet(metricsStartKey, time.Now())
+	}
+	observe := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			started, ok := tx.InstanceGet(metricsStartKey)
+			if !ok {
+				return
+			}
+			table := tx.Statement.Table
+			duration.WithLabelValues(operation, table).Observe(time.Since(started.(time.Time)).Seconds())
+			if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+				failures.WithLabelValues(operation, table).Inc()
+			}
+		}
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register("metrics:before_create", start),
+		callbacks.Create().After("*").Register("metrics:after_create", observe("create")),
+		callbacks.Query().Before("*").Register("metrics:before_query", start),
+		callbacks.Query().After("*").Register("metrics:after_query", observe("query")),
+		callbacks.Update().Before("*").Register("metrics:before_update", start),
+		callbacks.Update().After("*").Register("metrics:after_update", observe("update")),
+		callbacks.Delete().Before("*").Register("metrics:before_delete", start),
+		callbacks.Delete().After("*").Register("metrics:after_delete", observe("delete")),
+		callbacks.Row().Before("*").Register("metrics:before_row", start),
+		callbacks.Row().After("*").Register("metrics:after_row", observe("row")),
+		callbacks.Raw().Before("*").Register("metrics:before_raw", start),
+		callbacks.Raw().After("*").Register("metrics:after_raw", observe("raw")),
+	} {
+		if err != nil {
+			return fmt.Errorf("err registering metrics callback:%w", err)
+		}
+	}
+	return nil
+}
+
+var (
+	questionsDesc = prometheus.NewDesc("questions_total", "Questions in the library, by organization.",
+		[]string{"organization"}, nil)
+	optionsDesc = prometheus.NewDesc("question_options_total", "Options of the questions in the library, by organization.",
+		[]string{"organization"}, nil)
+)
+
+// libraryCollector counts questions and options when scraped, so the gauges can not drift from the tables.
+type libraryCollector struct {
+	db *gorm.DB
+}
+
+type organizationCount struct {
+	OrganizationID string
+	Count          int
+}
+
+func (c libraryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- questionsDesc
+	ch <- optionsDesc
+}
+
+func (c libraryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), METRICS_LIBRARY_TIMEOUT)
+	defer cancel()
+	db := c.db.WithContext(ctx)
+
+	var questions, options []organizationCount
+	err := db.Raw("SELECT organization_id, COUNT(*) AS count FROM question GROUP BY organization_id").
+		Scan(&questions).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(questionsDesc, fmt.Errorf("err counting questions:%w", err))
+		return
+	}
+	err = db.Raw(`SELECT organization_id, COUNT(*) AS count FROM "option" GROUP BY organization_id`).Scan(&options).Error
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(optionsDesc, fmt.Errorf("err counting options:%w", err))
+		return
+	}
+
+	for _, count := range questions {
+		ch <- prometheus.MustNewConstMetric(questionsDesc, prometheus.GaugeValue, float64(count.Count), count.OrganizationID)
+	}
+	for _, count := range options {
+		ch <- prometheus.MustNewConstMetric(optionsDesc, prometheus.GaugeValue, float64(count.Count), count.OrganizationID)
+	}
+}
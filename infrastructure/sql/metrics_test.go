@@ -0,0 +1,88 @@
+package sql_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	db := sqltest.Open(t)
+	registry := prometheus.NewRegistry()
+	if err := sql.RegisterMetrics(db, registry); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	options := []domain.Option{{Body: "a", Correct: true}, {Body: "b"}, {Body: "c"}}
+	if err := sql.NewRepo(db).Add(ctx, domain.Question{ID: 1, Body: "one", Options: options}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sql.NewRepo(db).ForOrganization("acme").Add(ctx, domain.Question{ID: 2, Body: "two", Options: options[:2]}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sql.NewRepo(db).Get(ctx, 3); err == nil {
+		t.Fatal("got a question that does not exist")
+	}
+	_ = db.Exec("SELECT * FROM no_such_table").Error
+
+	expected := `
+# HELP question_options_total Options of the questions in the library, by organization.
+# TYPE question_options_total gauge
+question_options_total{organization="acme"} 2
+question_options_total{organization="default"} 3
+# HELP questions_total Questions in the library, by organization.
+# TYPE questions_total gauge
+questions_total{organization="acme"} 1
+questions_total{organization="default"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "questions_total", "question_options_total"); err != nil {
+		t.Error(err)
+	}
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string]bool{}
+	for _, family := range families {
+		found[family.GetName()] = true
+	}
+	for _, name := range []string{"db_query_duration_seconds", "db_query_errors_total", "go_sql_open_connections"} {
+		if !found[name] {
+			t.Errorf("metric %s is not exposed", name)
+		}
+	}
+	if failed := failedQueries(t, registry, "raw"); failed != 1 {
+		t.Errorf("failed raw queries counted %v, expected 1", failed)
+	}
+}
+
+// failedQueries reads the failed queries of the operation back from the registry, whatever their table.
+func failedQueries(t *testing.T, registry *prometheus.Registry, operation string) float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, family := range families {
+		if family.GetName() != "db_query_errors_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "operation" && label.GetValue() == operation {
+					total += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS question(
+    id INTEGER PRIMARY KEY,
+    organization_id TEXT NOT NULL DEFAULT 'default',
+    body TEXT NOT NULL,
+    difficulty INTEGER NOT NULL DEFAULT 0,
+    estimated_seconds INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    explanation TEXT NOT NULL DEFAULT '',
+    reveal TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS option(
+    id SERIAL PRIMARY KEY,
+    body TEXT NOT NULL,
+    correct BOOLEAN NOT NULL,
+    question_id INTEGER NOT NULL,
+    feedback TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
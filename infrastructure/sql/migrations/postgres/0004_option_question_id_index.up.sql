@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS options_question_id_idx ON option(question_id);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS attachment(
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL DEFAULT 'default',
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS question_attachment(
+    question_id INTEGER NOT NULL,
+    attachment_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY(question_id, position),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY(attachment_id) REFERENCES attachment(id)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS question_attachment_attachment_id_idx;
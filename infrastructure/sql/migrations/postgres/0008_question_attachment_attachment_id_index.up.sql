@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS question_attachment_attachment_id_idx ON question_attachment(attachment_id);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS option_attachment_attachment_id_idx;
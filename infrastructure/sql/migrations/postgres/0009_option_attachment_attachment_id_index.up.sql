@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS option_attachment_attachment_id_idx ON option_attachment(attachment_id);
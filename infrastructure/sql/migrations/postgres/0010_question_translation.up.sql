@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS question_translation(
+    question_id INTEGER NOT NULL,
+    locale TEXT NOT NULL,
+    body TEXT NOT NULL,
+    PRIMARY KEY(question_id, locale),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS option_translation(
+    question_id INTEGER NOT NULL,
+    locale TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    correct BOOLEAN NOT NULL,
+    PRIMARY KEY(question_id, locale, position),
+    FOREIGN KEY(question_id, locale) REFERENCES question_translation(question_id, locale) ON DELETE CASCADE ON UPDATE CASCADE
+);
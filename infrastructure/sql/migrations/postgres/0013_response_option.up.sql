@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS response_option(
+    response_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY(response_id, position),
+    FOREIGN KEY(response_id) REFERENCES response(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS question_tag(
+    question_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY(question_id, tag),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
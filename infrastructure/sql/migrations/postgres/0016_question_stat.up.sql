@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS question_stat(
+    question_id INTEGER PRIMARY KEY,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL,
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
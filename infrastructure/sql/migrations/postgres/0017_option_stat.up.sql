@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS option_stat(
+    question_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    picks INTEGER NOT NULL,
+    PRIMARY KEY(question_id, position),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
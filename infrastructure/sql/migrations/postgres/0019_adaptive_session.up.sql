@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS adaptive_session(
+    id TEXT PRIMARY KEY,
+    organization_id TEXT NOT NULL DEFAULT 'default',
+    candidate TEXT NOT NULL,
+    ability DOUBLE PRECISION NOT NULL,
+    standard_error DOUBLE PRECISION NOT NULL,
+    max_items INTEGER NOT NULL,
+    standard_error_threshold DOUBLE PRECISION NOT NULL,
+    next_question_id INTEGER,
+    finished BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
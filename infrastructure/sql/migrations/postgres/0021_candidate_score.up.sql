@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS candidate_score(
+    organization_id TEXT NOT NULL,
+    candidate TEXT NOT NULL,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL,
+    PRIMARY KEY(organization_id, candidate)
+);
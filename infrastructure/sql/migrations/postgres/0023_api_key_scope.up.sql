@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS api_key_scope(
+    api_key_id TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    PRIMARY KEY(api_key_id, scope),
+    FOREIGN KEY(api_key_id) REFERENCES api_key(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS audit_log_append_only();
@@ -0,0 +1,57 @@
+ALTER TABLE option DROP CONSTRAINT option_organization_id_question_id_fkey;
+ALTER TABLE question_attachment DROP CONSTRAINT question_attachment_organization_id_question_id_fkey;
+ALTER TABLE option_translation DROP CONSTRAINT option_translation_organization_id_question_id_locale_fkey;
+ALTER TABLE question_translation DROP CONSTRAINT question_translation_organization_id_question_id_fkey;
+ALTER TABLE response DROP CONSTRAINT response_organization_id_question_id_fkey;
+ALTER TABLE question_tag DROP CONSTRAINT question_tag_organization_id_question_id_fkey;
+ALTER TABLE question_stat DROP CONSTRAINT question_stat_organization_id_question_id_fkey;
+ALTER TABLE option_stat DROP CONSTRAINT option_stat_organization_id_question_id_fkey;
+ALTER TABLE item_parameter DROP CONSTRAINT item_parameter_organization_id_question_id_fkey;
+
+ALTER TABLE question DROP CONSTRAINT question_pkey;
+ALTER TABLE question ADD PRIMARY KEY(id);
+
+DROP INDEX IF EXISTS options_question_id_idx;
+ALTER TABLE option DROP COLUMN organization_id;
+CREATE INDEX IF NOT EXISTS options_question_id_idx ON option(question_id);
+ALTER TABLE option ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_attachment DROP CONSTRAINT question_attachment_pkey;
+ALTER TABLE question_attachment DROP COLUMN organization_id;
+ALTER TABLE question_attachment ADD PRIMARY KEY(question_id, position);
+ALTER TABLE question_attachment ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE option_translation DROP CONSTRAINT option_translation_pkey;
+ALTER TABLE question_translation DROP CONSTRAINT question_translation_pkey;
+ALTER TABLE question_translation DROP COLUMN organization_id;
+ALTER TABLE question_translation ADD PRIMARY KEY(question_id, locale);
+ALTER TABLE question_translation ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE option_translation DROP COLUMN organization_id;
+ALTER TABLE option_translation ADD PRIMARY KEY(question_id, locale, position);
+ALTER TABLE option_translation ADD FOREIGN KEY(question_id, locale) REFERENCES question_translation(question_id, locale) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE response DROP CONSTRAINT response_organization_id_question_id_candidate_key;
+ALTER TABLE response DROP COLUMN organization_id;
+ALTER TABLE response ADD UNIQUE(question_id, candidate);
+ALTER TABLE response ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_tag DROP CONSTRAINT question_tag_pkey;
+ALTER TABLE question_tag DROP COLUMN organization_id;
+ALTER TABLE question_tag ADD PRIMARY KEY(question_id, tag);
+ALTER TABLE question_tag ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_stat DROP CONSTRAINT question_stat_pkey;
+ALTER TABLE question_stat DROP COLUMN organization_id;
+ALTER TABLE question_stat ADD PRIMARY KEY(question_id);
+ALTER TABLE question_stat ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE option_stat DROP CONSTRAINT option_stat_pkey;
+ALTER TABLE option_stat DROP COLUMN organization_id;
+ALTER TABLE option_stat ADD PRIMARY KEY(question_id, position);
+ALTER TABLE option_stat ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE item_parameter DROP CONSTRAINT item_parameter_pkey;
+ALTER TABLE item_parameter DROP COLUMN organization_id;
+ALTER TABLE item_parameter ADD PRIMARY KEY(question_id);
+ALTER TABLE item_parameter ADD FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,75 @@
+ALTER TABLE option DROP CONSTRAINT option_question_id_fkey;
+ALTER TABLE question_attachment DROP CONSTRAINT question_attachment_question_id_fkey;
+ALTER TABLE option_translation DROP CONSTRAINT option_translation_question_id_locale_fkey;
+ALTER TABLE question_translation DROP CONSTRAINT question_translation_question_id_fkey;
+ALTER TABLE response DROP CONSTRAINT response_question_id_fkey;
+ALTER TABLE question_tag DROP CONSTRAINT question_tag_question_id_fkey;
+ALTER TABLE question_stat DROP CONSTRAINT question_stat_question_id_fkey;
+ALTER TABLE option_stat DROP CONSTRAINT option_stat_question_id_fkey;
+ALTER TABLE item_parameter DROP CONSTRAINT item_parameter_question_id_fkey;
+
+ALTER TABLE question DROP CONSTRAINT question_pkey;
+ALTER TABLE question ADD PRIMARY KEY(organization_id, id);
+
+ALTER TABLE option ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE option SET organization_id = question.organization_id FROM question WHERE question.id = option.question_id;
+ALTER TABLE option ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE option ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+DROP INDEX IF EXISTS options_question_id_idx;
+CREATE INDEX IF NOT EXISTS options_question_id_idx ON option(organization_id, question_id);
+
+ALTER TABLE question_attachment ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE question_attachment SET organization_id = question.organization_id FROM question WHERE question.id = question_attachment.question_id;
+ALTER TABLE question_attachment ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE question_attachment DROP CONSTRAINT question_attachment_pkey;
+ALTER TABLE question_attachment ADD PRIMARY KEY(organization_id, question_id, position);
+ALTER TABLE question_attachment ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_translation ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE question_translation SET organization_id = question.organization_id FROM question WHERE question.id = question_translation.question_id;
+ALTER TABLE question_translation ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE question_translation DROP CONSTRAINT question_translation_pkey;
+ALTER TABLE question_translation ADD PRIMARY KEY(organization_id, question_id, locale);
+ALTER TABLE question_translation ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE option_translation ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE option_translation SET organization_id = question.organization_id FROM question WHERE question.id = option_translation.question_id;
+ALTER TABLE option_translation ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE option_translation DROP CONSTRAINT option_translation_pkey;
+ALTER TABLE option_translation ADD PRIMARY KEY(organization_id, question_id, locale, position);
+ALTER TABLE option_translation ADD FOREIGN KEY(organization_id, question_id, locale) REFERENCES question_translation(organization_id, question_id, locale) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE response ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE response SET organization_id = question.organization_id FROM question WHERE question.id = response.question_id;
+ALTER TABLE response ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE response DROP CONSTRAINT response_question_id_candidate_key;
+ALTER TABLE response ADD UNIQUE(organization_id, question_id, candidate);
+ALTER TABLE response ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_tag ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE question_tag SET organization_id = question.organization_id FROM question WHERE question.id = question_tag.question_id;
+ALTER TABLE question_tag ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE question_tag DROP CONSTRAINT question_tag_pkey;
+ALTER TABLE question_tag ADD PRIMARY KEY(organization_id, question_id, tag);
+ALTER TABLE question_tag ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE question_stat ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE question_stat SET organization_id = question.organization_id FROM question WHERE question.id = question_stat.question_id;
+ALTER TABLE question_stat ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE question_stat DROP CONSTRAINT question_stat_pkey;
+ALTER TABLE question_stat ADD PRIMARY KEY(organization_id, question_id);
+ALTER TABLE question_stat ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE option_stat ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE option_stat SET organization_id = question.organization_id FROM question WHERE question.id = option_stat.question_id;
+ALTER TABLE option_stat ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE option_stat DROP CONSTRAINT option_stat_pkey;
+ALTER TABLE option_stat ADD PRIMARY KEY(organization_id, question_id, position);
+ALTER TABLE option_stat ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE item_parameter ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
+UPDATE item_parameter SET organization_id = question.organization_id FROM question WHERE question.id = item_parameter.question_id;
+ALTER TABLE item_parameter ALTER COLUMN organization_id DROP DEFAULT;
+ALTER TABLE item_parameter DROP CONSTRAINT item_parameter_pkey;
+ALTER TABLE item_parameter ADD PRIMARY KEY(organization_id, question_id);
+ALTER TABLE item_parameter ADD FOREIGN KEY(organization_id, question_id) REFERENCES question(organization_id, id) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS attachment(
+    id TEXT PRIMARY KEY,
+    filename TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL
+)
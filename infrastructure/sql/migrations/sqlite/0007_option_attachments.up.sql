@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS option_attachment(
+    option_id INTEGER NOT NULL,
+    attachment_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY(option_id, position),
+    FOREIGN KEY(option_id) REFERENCES option(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY(attachment_id) REFERENCES attachment(id)
+)
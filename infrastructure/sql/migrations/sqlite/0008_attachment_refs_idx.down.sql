@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS question_attachment_attachment_id_idx;
+DROP INDEX IF EXISTS option_attachment_attachment_id_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS question_attachment_attachment_id_idx on question_attachment(attachment_id);
+CREATE INDEX IF NOT EXISTS option_attachment_attachment_id_idx on option_attachment(attachment_id);
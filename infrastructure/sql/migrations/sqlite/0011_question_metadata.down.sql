@@ -0,0 +1,3 @@
+ALTER TABLE question DROP COLUMN difficulty;
+ALTER TABLE question DROP COLUMN estimated_seconds;
+ALTER TABLE question DROP COLUMN points;
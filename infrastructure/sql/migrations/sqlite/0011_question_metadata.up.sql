@@ -0,0 +1,3 @@
+ALTER TABLE question ADD COLUMN difficulty INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE question ADD COLUMN estimated_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE question ADD COLUMN points INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE question DROP COLUMN explanation;
+ALTER TABLE question DROP COLUMN reveal;
+ALTER TABLE option DROP COLUMN feedback;
@@ -0,0 +1,3 @@
+ALTER TABLE question ADD COLUMN explanation TEXT NOT NULL DEFAULT '';
+ALTER TABLE question ADD COLUMN reveal TEXT NOT NULL DEFAULT '';
+ALTER TABLE option ADD COLUMN feedback TEXT NOT NULL DEFAULT '';
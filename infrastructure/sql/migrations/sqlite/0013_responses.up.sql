@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS response(
+    id INTEGER PRIMARY KEY,
+    question_id INTEGER NOT NULL,
+    candidate TEXT NOT NULL,
+    correct BOOL NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE(question_id, candidate),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+)
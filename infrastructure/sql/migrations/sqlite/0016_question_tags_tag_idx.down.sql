@@ -0,0 +1 @@
+DROP INDEX IF EXISTS question_tag_tag_idx;
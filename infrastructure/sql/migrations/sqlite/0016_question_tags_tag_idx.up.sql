@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS question_tag_tag_idx on question_tag(tag);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS candidate_score(
+    candidate TEXT PRIMARY KEY,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL
+)
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS item_parameter(
+    question_id INTEGER PRIMARY KEY,
+    discrimination REAL NOT NULL,
+    difficulty REAL NOT NULL,
+    responses INTEGER NOT NULL,
+    calibrated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+)
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS adaptive_session(
+    id TEXT PRIMARY KEY,
+    candidate TEXT NOT NULL,
+    ability REAL NOT NULL,
+    standard_error REAL NOT NULL,
+    max_items INTEGER NOT NULL,
+    standard_error_threshold REAL NOT NULL,
+    next_question_id INTEGER,
+    finished BOOL NOT NULL,
+    created_at TIMESTAMP NOT NULL
+)
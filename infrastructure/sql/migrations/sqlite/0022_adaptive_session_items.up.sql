@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS adaptive_session_item(
+    session_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    question_id INTEGER NOT NULL,
+    correct BOOL NOT NULL,
+    PRIMARY KEY(session_id, position),
+    FOREIGN KEY(session_id) REFERENCES adaptive_session(id) ON DELETE CASCADE ON UPDATE CASCADE
+)
@@ -0,0 +1 @@
+ALTER TABLE question DROP COLUMN organization_id;
@@ -0,0 +1 @@
+ALTER TABLE question ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
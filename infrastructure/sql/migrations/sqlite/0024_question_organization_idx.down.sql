@@ -0,0 +1 @@
+DROP INDEX IF EXISTS question_organization_id_idx;
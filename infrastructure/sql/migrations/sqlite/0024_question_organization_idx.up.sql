@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS question_organization_id_idx on question(organization_id);
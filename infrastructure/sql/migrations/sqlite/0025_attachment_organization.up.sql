@@ -0,0 +1 @@
+ALTER TABLE attachment ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
@@ -0,0 +1 @@
+ALTER TABLE adaptive_session DROP COLUMN organization_id;
@@ -0,0 +1 @@
+ALTER TABLE adaptive_session ADD COLUMN organization_id TEXT NOT NULL DEFAULT 'default';
@@ -0,0 +1,9 @@
+CREATE TABLE candidate_score_global(
+    candidate TEXT PRIMARY KEY,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL
+);
+INSERT INTO candidate_score_global(candidate, responses, correct)
+    SELECT candidate, SUM(responses), SUM(correct) FROM candidate_score GROUP BY candidate;
+DROP TABLE candidate_score;
+ALTER TABLE candidate_score_global RENAME TO candidate_score;
@@ -0,0 +1,11 @@
+CREATE TABLE candidate_score_organization(
+    organization_id TEXT NOT NULL,
+    candidate TEXT NOT NULL,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL,
+    PRIMARY KEY (organization_id, candidate)
+);
+INSERT INTO candidate_score_organization(organization_id, candidate, responses, correct)
+    SELECT 'default', candidate, responses, correct FROM candidate_score;
+DROP TABLE candidate_score;
+ALTER TABLE candidate_score_organization RENAME TO candidate_score;
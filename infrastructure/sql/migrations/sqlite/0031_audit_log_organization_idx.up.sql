@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS audit_log_organization_id_idx on audit_log(organization_id, id);
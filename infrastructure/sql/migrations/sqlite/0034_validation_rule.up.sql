@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS validation_rule(
+    organization_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    params TEXT NOT NULL DEFAULT '[]',
+    PRIMARY KEY(organization_id, position)
+)
@@ -0,0 +1 @@
+ALTER TABLE question ADD COLUMN updated_at TIMESTAMP;
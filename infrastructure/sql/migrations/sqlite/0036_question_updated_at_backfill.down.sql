@@ -0,0 +1 @@
+UPDATE question SET updated_at = NULL;
@@ -0,0 +1,4 @@
+UPDATE question SET updated_at = (
+    SELECT MAX(created_at) FROM audit_log
+    WHERE audit_log.question_id = question.id AND audit_log.organization_id = question.organization_id
+) WHERE updated_at IS NULL;
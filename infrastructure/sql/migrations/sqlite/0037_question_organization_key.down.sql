@@ -0,0 +1,135 @@
+CREATE TABLE question_global_key(
+    id INTEGER PRIMARY KEY,
+    organization_id TEXT NOT NULL DEFAULT 'default',
+    body TEXT NOT NULL,
+    difficulty INTEGER NOT NULL DEFAULT 0,
+    estimated_seconds INTEGER NOT NULL DEFAULT 0,
+    points INTEGER NOT NULL DEFAULT 0,
+    explanation TEXT NOT NULL DEFAULT '',
+    reveal TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP
+);
+INSERT INTO question_global_key(id, organization_id, body, difficulty, estimated_seconds, points, explanation, reveal, updated_at)
+    SELECT id, organization_id, body, difficulty, estimated_seconds, points, explanation, reveal, updated_at FROM question;
+
+CREATE TABLE option_global_key(
+    id INTEGER PRIMARY KEY,
+    body TEXT NOT NULL,
+    correct BOOL NOT NULL,
+    question_id INTEGER NOT NULL,
+    feedback TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO option_global_key(id, body, correct, question_id, feedback)
+    SELECT id, body, correct, question_id, feedback FROM option;
+
+CREATE TABLE question_attachment_global_key(
+    question_id INTEGER NOT NULL,
+    attachment_id TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY(question_id, position),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE,
+    FOREIGN KEY(attachment_id) REFERENCES attachment(id)
+);
+INSERT INTO question_attachment_global_key(question_id, attachment_id, position)
+    SELECT question_id, attachment_id, position FROM question_attachment;
+
+CREATE TABLE question_translation_global_key(
+    question_id INTEGER NOT NULL,
+    locale TEXT NOT NULL,
+    body TEXT NOT NULL,
+    PRIMARY KEY(question_id, locale),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO question_translation_global_key(question_id, locale, body)
+    SELECT question_id, locale, body FROM question_translation;
+
+CREATE TABLE option_translation_global_key(
+    question_id INTEGER NOT NULL,
+    locale TEXT NOT NULL,
+    position INTEGER NOT NULL,
+    body TEXT NOT NULL,
+    correct BOOL NOT NULL,
+    PRIMARY KEY(question_id, locale, position),
+    FOREIGN KEY(question_id, locale) REFERENCES question_translation(question_id, locale) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO option_translation_global_key(question_id, locale, position, body, correct)
+    SELECT question_id, locale, position, body, correct FROM option_translation;
+
+CREATE TABLE response_global_key(
+    id INTEGER PRIMARY KEY,
+    question_id INTEGER NOT NULL,
+    candidate TEXT NOT NULL,
+    correct BOOL NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE(question_id, candidate),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO response_global_key(id, question_id, candidate, correct, created_at)
+    SELECT id, question_id, candidate, correct, created_at FROM response;
+
+CREATE TABLE question_tag_global_key(
+    question_id INTEGER NOT NULL,
+    tag TEXT NOT NULL,
+    PRIMARY KEY(question_id, tag),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO question_tag_global_key(question_id, tag)
+    SELECT question_id, tag FROM question_tag;
+
+CREATE TABLE question_stat_global_key(
+    question_id INTEGER PRIMARY KEY,
+    responses INTEGER NOT NULL,
+    correct INTEGER NOT NULL,
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO question_stat_global_key(question_id, responses, correct)
+    SELECT question_id, responses, correct FROM question_stat;
+
+CREATE TABLE option_stat_global_key(
+    question_id INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    picks INTEGER NOT NULL,
+    PRIMARY KEY(question_id, position),
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO option_stat_global_key(question_id, position, picks)
+    SELECT question_id, position, picks FROM option_stat;
+
+CREATE TABLE item_parameter_global_key(
+    question_id INTEGER PRIMARY KEY,
+    discrimination REAL NOT NULL,
+    difficulty REAL NOT NULL,
+    responses INTEGER NOT NULL,
+    calibrated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY(question_id) REFERENCES question(id) ON DELETE CASCADE ON UPDATE CASCADE
+);
+INSERT INTO item_parameter_global_key(question_id, discrimination, difficulty, responses, calibrated_at)
+    SELECT question_id, discrimination, difficulty, responses, calibrated_at FROM item_parameter;
+
+DROP TABLE item_parameter;
+DROP TABLE option_stat;
+DROP TABLE question_stat;
+DROP TABLE question_tag;
+DROP TABLE response;
+DROP TABLE option_translation;
+DROP TABLE question_translation;
+DROP TABLE question_attachment;
+DROP TABLE option;
+DROP TABLE question;
+
+ALTER TABLE question_global_key RENAME TO question;
+ALTER TABLE option_global_key RENAME TO option;
+ALTER TABLE question_attachment_global_key RENAME TO question_attachment;
+ALTER TABLE question_translation_global_key RENAME TO question_translation;
+ALTER TABLE option_translation_global_key RENAME TO option_translation;
+ALTER TABLE response_global_key RENAME TO response;
+ALTER TABLE question_tag_global_key RENAME TO question_tag;
+ALTER TABLE question_stat_global_key RENAME TO question_stat;
+ALTER TABLE option_stat_global_key RENAME TO option_stat;
+ALTER TABLE item_parameter_global_key RENAME TO item_parameter;
+
+CREATE INDEX IF NOT EXISTS question_organization_id_idx on question(organization_id);
+CREATE INDEX IF NOT EXISTS options_question_id_idx on option(question_id);
+CREATE INDEX IF NOT EXISTS question_attachment_attachment_id_idx on question_attachment(attachment_id);
+CREATE INDEX IF NOT EXISTS question_tag_tag_idx on question_tag(tag);
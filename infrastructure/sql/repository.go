@@ -1,15 +1,24 @@
 package sql
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/togglhire/backend-homework/domain"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Repository only sees the data of one organization, every query on questions and on the tables keyed by
+// question is scoped to it. Changes of questions are audited as made by the actor. Calls on questions give up
+// after the query timeout, if any.
 type Repository struct {
-	db *gorm.DB
+	db           *gorm.DB
+	organization string
+	actor        domain.Actor
+	queryTimeout time.Duration
 }
 
 type Tabler interface {
@@ -17,10 +26,13 @@ type Tabler interface {
 }
 
 type Option struct {
-	ID         int    `db:"id"`
-	Body       string `db:"body"`
-	Correct    bool   `db:"correct"`
-	QuestionID int    `db:"question_id"`
+	ID             int    `db:"id"`
+	OrganizationID string `db:"organization_id"`
+	Body           string `db:"body"`
+	Correct        bool   `db:"correct"`
+	QuestionID     int    `db:"question_id"`
+	Feedback       string `db:"feedback"`
+	Attachments    []OptionAttachment
 }
 
 type OrderedOptions []Option
@@ -35,31 +47,146 @@ func (Option) TableName() string {
 	return "option"
 }
 
+// Question is keyed by organization and id, so every organization numbers its questions on its own.
 type Question struct {
-	ID      int    `db:"id"`
-	Body    string `db:"body"`
-	Options []Option
+	ID               int    `db:"id" gorm:"primaryKey;autoIncrement:false"`
+	OrganizationID   string `db:"organization_id" gorm:"primaryKey"`
+	Body             string `db:"body"`
+	Difficulty       int    `db:"difficulty"`
+	EstimatedSeconds int    `db:"estimated_seconds"`
+	Points           int    `db:"points"`
+	Explanation      string `db:"explanation"`
+	Reveal           string `db:"reveal"`
+	// UpdatedAt is when the question was last written, unknown for questions written before it was recorded
+	UpdatedAt   *time.Time           `db:"updated_at"`
+	Options     []Option             `gorm:"foreignKey:OrganizationID,QuestionID;references:OrganizationID,ID"`
+	Attachments []QuestionAttachment `gorm:"foreignKey:OrganizationID,QuestionID;references:OrganizationID,ID"`
+	Tags        []QuestionTag        `gorm:"foreignKey:OrganizationID,QuestionID;references:OrganizationID,ID"`
 }
 
-type OrderedQuestions []Question
+type QuestionTag struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int    `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Tag            string `db:"tag" gorm:"primaryKey"`
+}
 
-func (a OrderedQuestions) Len() int { return len(a) }
-func (a OrderedQuestions) Less(i, j int) bool {
-	return a[i].ID > a[j].ID
+func (QuestionTag) TableName() string {
+	return "question_tag"
 }
-func (a OrderedQuestions) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 
 func (Question) TableName() string {
 	return "question"
 }
 
 func NewRepo(db *gorm.DB) Repository {
-	return Repository{db: db}
+	return Repository{db: db, organization: domain.DefaultOrganization}
+}
+
+// ForOrganization returns a copy of the repository bound to another organization.
+func (r Repository) ForOrganization(organization string) Repository {
+	r.organization = organization
+	return r
+}
+
+// WithQueryTimeout returns a copy of the repository bounding every call on questions by timeout, 0 leaves them
+// bounded by their context only.
+func (r Repository) WithQueryTimeout(timeout time.Duration) Repository {
+	r.queryTimeout = timeout
+	return r
 }
 
-func (r Repository) GetAll() ([]domain.Question, error) {
+// withContext returns the connection to run a call with, cancel must be called once the call is done.
+func (r Repository) withContext(ctx context.Context) (*gorm.DB, context.CancelFunc) {
+	if r.queryTimeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, r.queryTimeout)
+		return r.db.WithContext(ctx), cancel
+	}
+	return r.db.WithContext(ctx), func() {}
+}
+
+// GetOrganizations lists the organizations owning at least one question or attachment.
+func (r Repository) GetOrganizations() ([]string, error) {
+	var organizations []string
+	err := r.db.Raw("SELECT organization_id FROM question UNION SELECT organization_id FROM attachment ORDER BY organization_id").
+		Scan(&organizations).Error
+	if err != nil {
+		return nil, fmt.Errorf("err query get organizations:%w", err)
+	}
+	return organizations, nil
+}
+
+// checkQuestionExists fails with domain.ErrNoQuestionFound when the question belongs to another organization.
+func (r Repository) checkQuestionExists(db *gorm.DB, id int) error {
+	var count int64
+	if err := db.Model(&Question{}).Where("id = ? AND organization_id = ?", id, r.organization).Count(&count).Error; err != nil {
+		return fmt.Errorf("err query question exists:%w", err)
+	}
+	if count == 0 {
+		return domain.ErrNoQuestionFound
+	}
+	return nil
+}
+
+func (r Repository) GetAll(ctx context.Context) ([]domain.Question, error) {
+	return r.Find(ctx, domain.QuestionFilter{})
+}
+
+func (r Repository) Find(ctx context.Context, filter domain.QuestionFilter) (_ []domain.Question, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	query := db.Preload("Options.Attachments").Preload("Attachments").Preload("Tags").Where("organization_id = ?", r.organization)
+
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("id IN (?)", db.Model(&QuestionTag{}).Select("question_id").Where("organization_id = ? AND tag = ?", r.organization, tag))
+	}
+	if len(filter.Difficulties) > 0 {
+		ranks := make([]int, 0, len(filter.Difficulties))
+		for _, difficulty := range filter.Difficulties {
+			ranks = append(ranks, difficulty.Rank())
+		}
+		query = query.Where("difficulty IN ?", ranks)
+	}
+	if filter.MinPoints != nil {
+		query = query.Where("points >= ?", *filter.MinPoints)
+	}
+	if filter.MaxPoints != nil {
+		query = query.Where("points <= ?", *filter.MaxPoints)
+	}
+	if filter.MinEstimatedSeconds != nil {
+		query = query.Where("estimated_seconds >= ?", *filter.MinEstimatedSeconds)
+	}
+	if filter.MaxEstimatedSeconds != nil {
+		query = query.Where("estimated_seconds <= ?", *filter.MaxEstimatedSeconds)
+	}
+	for _, condition := range filter.Conditions {
+		where, args, err := conditionSQL(condition)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(where, args...)
+	}
+
+	for _, field := range filter.Sort {
+		if !domain.IsSortableQuestionField(field.Field) {
+			return nil, fmt.Errorf("err sorting questions by unknown field %s", field.Field)
+		}
+		if field.Field == "updated_at" {
+			// questions not written since updated_at was recorded have none, they sort as the oldest on every
+			// dialect, sqlite puts NULLs first and postgres last otherwise
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "updated_at IS NULL", Raw: true}, Desc: !field.Desc})
+		}
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: field.Field}, Desc: field.Desc})
+	}
+	// newest first keeps the order stable whatever the sort fields are
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: "id"}, Desc: true})
+
 	var rows []Question
-	err := r.db.Preload("Options").Find(&rows).Error
+	err = query.Find(&rows).Error
 
 	if err != nil {
 		return nil, fmt.Errorf("err query get all questions:%w", err)
@@ -68,16 +195,59 @@ func (r Repository) GetAll() ([]domain.Question, error) {
 	return convertToDomain(rows), nil
 }
 
-func (r Repository) Add(question domain.Question) error {
-	tx := r.db.Begin()
+func (r Repository) Get(ctx context.Context, id int) (_ domain.Question, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	return r.getQuestion(db, id)
+}
+
+func (r Repository) getQuestion(db *gorm.DB, id int) (domain.Question, error) {
+	var rows []Question
+	err := db.Preload("Options.Attachments").Preload("Attachments").Preload("Tags").Where("id = ? AND organization_id = ?", id, r.organization).Find(&rows).Error
+	if err != nil {
+		return domain.Question{}, fmt.Errorf("err query get question:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.Question{}, domain.ErrNoQuestionFound
+	}
+
+	return convertToDomain(rows)[0], nil
+}
+
+func (r Repository) Add(ctx context.Context, question domain.Question) (err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
+
+	dbQuestion := convertToDBModel(question, r.organization)
+	now := time.Now().UTC()
+	dbQuestion.UpdatedAt = &now
 
-	dbQuestion := convertToDBModel(question)
+	if err := r.checkAttachmentsExist(tx, question.AttachmentIDs()); err != nil {
+		tx.Rollback()
+		return err
+	}
 
 	if err := tx.Create(&dbQuestion).Error; err != nil {
 		tx.Rollback()
+		if uniqueViolation(err) {
+			return domain.ErrQuestionExists
+		}
 		return fmt.Errorf("err sql exec adding question:%w", err)
 	}
 
+	after, err := r.getQuestion(tx, dbQuestion.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if err := r.recordAudit(tx, domain.AuditCreate, dbQuestion.ID, nil, &after); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
 	// Commit the transaction
 	if err := tx.Commit().Error; err != nil {
 		_ = tx.Rollback()
@@ -87,66 +257,205 @@ func (r Repository) Add(question domain.Question) error {
 	return nil
 }
 
-func (r Repository) Update(question domain.Question) error {
+// Update replaces the question and returns the version it replaced, translations no longer matching its options
+// are dropped in the same transaction. The question is locked while it is read, so concurrent updates of it
+// each see the version they replace.
+func (r Repository) Update(ctx context.Context, question domain.Question) (_ domain.Question, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
 
-	tx := r.db.Begin()
+	dbQuestion := convertToDBModel(question, r.organization)
+	now := time.Now().UTC()
+	dbQuestion.UpdatedAt = &now
 
-	dbQuestion := convertToDBModel(question)
+	before, err := r.getQuestion(tx.Clauses(clause.Locking{Strength: "UPDATE"}), question.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Question{}, err
+	}
 
-	var dbQuestionExists Question
-	tx.First(&dbQuestionExists, question.ID)
-	if dbQuestionExists.ID != question.ID {
+	if err := r.checkAttachmentsExist(tx, question.AttachmentIDs()); err != nil {
 		_ = tx.Rollback()
-		return domain.ErrNoQuestionFound
+		return domain.Question{}, err
 	}
 
-	err := tx.Model(&dbQuestion).Updates(dbQuestion).Error
+	err = tx.Model(&dbQuestion).Select("body", "difficulty", "estimated_seconds", "points", "explanation", "reveal", "updated_at").Updates(dbQuestion).Error
 	if err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("err sql exec updating question:%w", err)
+		return domain.Question{}, fmt.Errorf("err sql exec updating question:%w", err)
 	}
 
-	err = tx.Exec(`DELETE FROM option where question_id = ?`, question.ID).Error
+	err = deleteQuestionChildren(tx, question.ID, r.organization)
 	if err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("err sql exec deleting option before creating:%w", err)
+		return domain.Question{}, err
+	}
+
+	// responses and their stats point at options by position, they mean nothing once the options change
+	if optionsChanged(before.Options, question.Options) {
+		if err := deleteQuestionResponses(tx, question.ID, r.organization); err != nil {
+			_ = tx.Rollback()
+			return domain.Question{}, err
+		}
+	}
+
+	if len(dbQuestion.Attachments) > 0 {
+		err = tx.Create(&dbQuestion.Attachments).Error
+		if err != nil {
+			_ = tx.Rollback()
+			return domain.Question{}, fmt.Errorf("err sql exec adding attachments on update:%w", err)
+		}
 	}
 
-	err = tx.Create(dbQuestion.Options).Error
+	if len(dbQuestion.Tags) > 0 {
+		err = tx.Create(&dbQuestion.Tags).Error
+		if err != nil {
+			_ = tx.Rollback()
+			return domain.Question{}, fmt.Errorf("err sql exec adding tags on update:%w", err)
+		}
+	}
+
+	err = tx.Create(&dbQuestion.Options).Error
 	if err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("err sql exec adding options on update:%w", err)
+		return domain.Question{}, fmt.Errorf("err sql exec adding options on update:%w", err)
+	}
 
+	if err := pruneTranslations(tx, question, r.organization); err != nil {
+		_ = tx.Rollback()
+		return domain.Question{}, err
+	}
+
+	after, err := r.getQuestion(tx, question.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Question{}, err
+	}
+	if err := r.recordAudit(tx, domain.AuditUpdate, question.ID, &before, &after); err != nil {
+		_ = tx.Rollback()
+		return domain.Question{}, err
 	}
 
 	err = tx.Commit().Error
 	if err != nil {
 		_ = tx.Rollback()
-		return fmt.Errorf("err commit trx update question:%w", err)
+		return domain.Question{}, fmt.Errorf("err commit trx update question:%w", err)
 	}
 
+	return before, nil
+}
+
+func (r Repository) Delete(ctx context.Context, id int) (err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
+
+	before, err := r.getQuestion(tx, id)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := deleteQuestionChildren(tx, id, r.organization); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := deleteQuestionTranslations(tx, id, r.organization); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := deleteQuestionResponses(tx, id, r.organization); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Exec(`DELETE FROM question where id = ? AND organization_id = ?`, id, r.organization).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err sql exec deleting question:%w", err)
+	}
+
+	if err := r.recordAudit(tx, domain.AuditDelete, id, &before, nil); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx delete question:%w", err)
+	}
+
+	return nil
+}
+
+// deleteQuestionChildren removes the options, attachment references and tags of a question, as sqlite does not
+// enforce foreign keys and cascades can not be relied on, through gorm so table names are quoted for the dialect.
+func deleteQuestionChildren(tx *gorm.DB, questionID int, organization string) error {
+	options := tx.Model(&Option{}).Select("id").Where("organization_id = ? AND question_id = ?", organization, questionID)
+	err := tx.Where("option_id IN (?)", options).Delete(&OptionAttachment{}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting option attachments:%w", err)
+	}
+
+	err = tx.Where("organization_id = ? AND question_id = ?", organization, questionID).Delete(&Option{}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting options:%w", err)
+	}
+
+	err = tx.Where("organization_id = ? AND question_id = ?", organization, questionID).Delete(&QuestionAttachment{}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting question attachments:%w", err)
+	}
+
+	err = tx.Where("organization_id = ? AND question_id = ?", organization, questionID).Delete(&QuestionTag{}).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting question tags:%w", err)
+	}
 	return nil
 }
 
+// optionsChanged tells whether an option was added, removed, reworded or had its correctness changed.
+func optionsChanged(before, after []domain.Option) bool {
+	if len(before) != len(after) {
+		return true
+	}
+	for i := range before {
+		if before[i].Body != after[i].Body || before[i].Correct != after[i].Correct {
+			return true
+		}
+	}
+	return false
+}
+
 func convertToDomain(questions []Question) []domain.Question {
 
-	var orderQuestions OrderedQuestions = questions
-	sort.Sort(orderQuestions)
 	domainQuestions := make([]domain.Question, 0)
 
-	for _, question := range orderQuestions {
+	for _, question := range questions {
 		options := make([]domain.Option, 0)
 
 		var orderOpt OrderedOptions = question.Options
 		sort.Sort(orderOpt)
 		for _, opt := range orderOpt {
-			options = append(options, domain.Option{Body: opt.Body, Correct: opt.Correct})
+			options = append(options, domain.Option{Body: opt.Body, Correct: opt.Correct, Feedback: opt.Feedback,
+				Attachments: optionAttachmentIDs(opt.Attachments)})
 		}
 
 		domainQuestion := domain.Question{
-			ID:      question.ID,
-			Body:    question.Body,
-			Options: options,
+			ID:               question.ID,
+			Body:             question.Body,
+			Options:          options,
+			Attachments:      questionAttachmentIDs(question.Attachments),
+			Difficulty:       domain.DifficultyFromRank(question.Difficulty),
+			EstimatedSeconds: question.EstimatedSeconds,
+			Points:           question.Points,
+			Explanation:      question.Explanation,
+			Reveal:           domain.Reveal(question.Reveal),
+			Tags:             tagNames(question.Tags),
 		}
 		domainQuestions = append(domainQuestions, domainQuestion)
 	}
@@ -154,16 +463,49 @@ func convertToDomain(questions []Question) []domain.Question {
 	return domainQuestions
 
 }
-func convertToDBModel(question domain.Question) Question {
+func convertToDBModel(question domain.Question, organization string) Question {
 	dbOptions := make([]Option, 0)
 
 	for _, opt := range question.Options {
-		dbOptions = append(dbOptions, Option{Body: opt.Body, Correct: opt.Correct})
+		dbOptions = append(dbOptions, Option{OrganizationID: organization, Body: opt.Body, Correct: opt.Correct, QuestionID: question.ID,
+			Feedback: opt.Feedback, Attachments: optionAttachmentsToDBModel(opt.Attachments)})
 	}
 
 	return Question{
-		ID:      question.ID,
-		Body:    question.Body,
-		Options: dbOptions,
+		ID:               question.ID,
+		OrganizationID:   organization,
+		Body:             question.Body,
+		Difficulty:       question.Difficulty.Rank(),
+		EstimatedSeconds: question.EstimatedSeconds,
+		Points:           question.Points,
+		Explanation:      question.Explanation,
+		Reveal:           string(question.Reveal),
+		Options:          dbOptions,
+		Attachments:      questionAttachmentsToDBModel(question.ID, organization, question.Attachments),
+		Tags:             tagsToDBModel(question.ID, organization, question.Tags),
+	}
+}
+
+func tagsToDBModel(questionID int, organization string, tags []string) []QuestionTag {
+	dbTags := make([]QuestionTag, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			dbTags = append(dbTags, QuestionTag{OrganizationID: organization, QuestionID: questionID, Tag: tag})
+		}
+	}
+	return dbTags
+}
+
+func tagNames(tags []QuestionTag) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		names = append(names, tag.Tag)
 	}
+	sort.Strings(names)
+	return names
 }
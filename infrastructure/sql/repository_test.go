@@ -0,0 +1,42 @@
+package sql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/domain/repotest"
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.QuestionRepository(t, func(t *testing.T) domain.QuestionRepository {
+		return sql.NewRepo(sqltest.Open(t))
+	})
+}
+
+func TestRepository_queryTimeout(t *testing.T) {
+	repo := sql.NewRepo(sqltest.Open(t)).WithQueryTimeout(time.Nanosecond)
+	ctx := context.Background()
+
+	calls := []struct {
+		name string
+		call func() error
+	}{
+		{"list", func() error { _, err := repo.GetAll(ctx); return err }},
+		{"delete translation", func() error { return repo.DeleteTranslation(ctx, 1, "es") }},
+		{"list audit entries", func() error { _, err := repo.GetAuditEntries(ctx, domain.AuditFilter{}); return err }},
+		{"get audit chain", func() error { _, err := repo.GetAuditChain(ctx); return err }},
+		{"get rule settings", func() error { _, err := repo.GetRuleSettings(ctx); return err }},
+	}
+	for _, c := range calls {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.call(); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("%s past the query timeout returned %v, expected %v", c.name, err, context.DeadlineExceeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package sql
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+)
+
+type Response struct {
+	ID             int       `db:"id"`
+	OrganizationID string    `db:"organization_id"`
+	QuestionID     int       `db:"question_id"`
+	Candidate      string    `db:"candidate"`
+	Correct        bool      `db:"correct"`
+	CreatedAt      time.Time `db:"created_at"`
+	Options        []ResponseOption
+}
+
+func (Response) TableName() string {
+	return "response"
+}
+
+type ResponseOption struct {
+	ResponseID int `db:"response_id" gorm:"primaryKey"`
+	Position   int `db:"position" gorm:"primaryKey"`
+}
+
+func (ResponseOption) TableName() string {
+	return "response_option"
+}
+
+func (r Repository) AddResponse(response domain.Response) (domain.Response, error) {
+	tx := r.db.Begin()
+
+	response, err := r.addResponse(tx, response)
+	if err != nil {
+		_ = tx.Rollback()
+		return domain.Response{}, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return domain.Response{}, fmt.Errorf("err commit trx add response:%w", err)
+	}
+
+	return response, nil
+}
+
+// addResponse stores the first response of the candidate to the question and counts it in the stats.
+func (r Repository) addResponse(tx *gorm.DB, response domain.Response) (domain.Response, error) {
+	if err := r.checkQuestionExists(tx, response.QuestionID); err != nil {
+		return domain.Response{}, err
+	}
+
+	var count int64
+	err := tx.Model(&Response{}).Where("organization_id = ? AND question_id = ? AND candidate = ?", r.organization, response.QuestionID, response.Candidate).Count(&count).Error
+	if err != nil {
+		return domain.Response{}, fmt.Errorf("err query response exists:%w", err)
+	}
+	if count > 0 {
+		return domain.Response{}, domain.ErrAlreadyAnswered
+	}
+
+	dbResponse := convertResponseToDBModel(response, r.organization)
+	if err := tx.Create(&dbResponse).Error; err != nil {
+		return domain.Response{}, fmt.Errorf("err sql exec adding response:%w", err)
+	}
+
+	if err := recordResponseStats(tx, dbResponse, r.organization); err != nil {
+		return domain.Response{}, err
+	}
+	return convertResponseToDomain(dbResponse), nil
+}
+
+func (r Repository) GetResponse(questionID int, candidate string) (domain.Response, error) {
+	var rows []Response
+	err := r.db.Preload("Options").Where("organization_id = ? AND question_id = ? AND candidate = ?", r.organization, questionID, candidate).Find(&rows).Error
+	if err != nil {
+		return domain.Response{}, fmt.Errorf("err query get response:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.Response{}, domain.ErrNoResponseFound
+	}
+	return convertResponseToDomain(rows[0]), nil
+}
+
+func (r Repository) GetResponses() ([]domain.Response, error) {
+	return findResponses(r.db.Where("organization_id = ?", r.organization))
+}
+
+func (r Repository) GetCandidateResponses(candidate string) ([]domain.Response, error) {
+	return findResponses(r.db.Where("organization_id = ? AND candidate = ?", r.organization, candidate))
+}
+
+func findResponses(query *gorm.DB) ([]domain.Response, error) {
+	var rows []Response
+	if err := query.Preload("Options").Order("id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("err query get responses:%w", err)
+	}
+	responses := make([]domain.Response, 0, len(rows))
+	for _, row := range rows {
+		responses = append(responses, convertResponseToDomain(row))
+	}
+	return responses, nil
+}
+
+func deleteQuestionResponses(tx *gorm.DB, questionID int, organization string) error {
+	if err := forgetQuestionStats(tx, questionID, organization); err != nil {
+		return err
+	}
+
+	err := tx.Exec(`DELETE FROM response_option where response_id IN (SELECT id FROM response where organization_id = ? AND question_id = ?)`, organization, questionID).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting response options:%w", err)
+	}
+	err = tx.Exec(`DELETE FROM response where organization_id = ? AND question_id = ?`, organization, questionID).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting responses:%w", err)
+	}
+
+	err = tx.Exec(`DELETE FROM item_parameter where organization_id = ? AND question_id = ?`, organization, questionID).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting item parameters:%w", err)
+	}
+	return nil
+}
+
+func convertResponseToDBModel(response domain.Response, organization string) Response {
+	options := make([]ResponseOption, 0, len(response.Selected))
+	for _, position := range response.Selected {
+		options = append(options, ResponseOption{Position: position})
+	}
+	return Response{
+		ID:             response.ID,
+		OrganizationID: organization,
+		QuestionID:     response.QuestionID,
+		Candidate:      response.Candidate,
+		Correct:        response.Correct,
+		CreatedAt:      response.CreatedAt,
+		Options:        options,
+	}
+}
+
+func convertResponseToDomain(response Response) domain.Response {
+	sort.Slice(response.Options, func(i, j int) bool { return response.Options[i].Position < response.Options[j].Position })
+	selected := make([]int, 0, len(response.Options))
+	for _, opt := range response.Options {
+		selected = append(selected, opt.Position)
+	}
+	return domain.Response{
+		ID:         response.ID,
+		QuestionID: response.QuestionID,
+		Candidate:  response.Candidate,
+		Selected:   selected,
+		Correct:    response.Correct,
+		CreatedAt:  response.CreatedAt,
+	}
+}
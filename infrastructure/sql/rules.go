@@ -0,0 +1,71 @@
+package sql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// ValidationRule is a rule an organization turned on, rules run in the order of their position.
+type ValidationRule struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	Position       int    `db:"position" gorm:"primaryKey"`
+	Name           string `db:"name"`
+	Params         string `db:"params"`
+}
+
+func (ValidationRule) TableName() string {
+	return "validation_rule"
+}
+
+func (r Repository) GetRuleSettings(ctx context.Context) (_ []domain.RuleSetting, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	var rows []ValidationRule
+	if err := db.Where("organization_id = ?", r.organization).Order("position").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("err query get rule settings:%w", err)
+	}
+	settings := make([]domain.RuleSetting, 0, len(rows))
+	for _, row := range rows {
+		setting := domain.RuleSetting{Name: row.Name}
+		if err := json.Unmarshal([]byte(row.Params), &setting.Params); err != nil {
+			return nil, fmt.Errorf("err decoding params of rule %s:%w", row.Name, err)
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+func (r Repository) SaveRuleSettings(ctx context.Context, settings []domain.RuleSetting) (err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
+
+	if err := tx.Where("organization_id = ?", r.organization).Delete(&ValidationRule{}).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err sql exec deleting rule settings:%w", err)
+	}
+	for i, setting := range settings {
+		params, err := json.Marshal(setting.Params)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("err encoding params of rule %s:%w", setting.Name, err)
+		}
+		row := ValidationRule{OrganizationID: r.organization, Position: i, Name: setting.Name, Params: string(params)}
+		if err := tx.Create(&row).Error; err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("err sql exec adding rule setting:%w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx save rule settings:%w", err)
+	}
+	return nil
+}
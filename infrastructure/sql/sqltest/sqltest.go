@@ -0,0 +1,53 @@
+// Package sqltest opens migrated databases for tests.
+package sqltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open migrates a fresh database for a test, a SQLite file unless TEST_DATABASE_URL points to a PostgreSQL
+// server, where every test gets a schema of its own.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		return sql.SetupSQLConnection(filepath.Join(t.TempDir(), "test.db"), logger.Discard)
+	}
+
+	admin, err := gorm.Open(postgres.Open(url), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+	db := sql.SetupSQLConnection(url+separator+"search_path="+schema, logger.Discard)
+	t.Cleanup(func() {
+		if conn, err := db.DB(); err == nil {
+			conn.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("err dropping test schema %s: %s", schema, err)
+		}
+		if conn, err := admin.DB(); err == nil {
+			conn.Close()
+		}
+	})
+	return db
+}
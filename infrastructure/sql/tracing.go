@@ -0,0 +1,82 @@
+package sql
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const TRACER_NAME = "github.com/togglhire/backend-homework/infrastructure/sql"
+
+const tracingSpanKey = "tracing:span"
+
+// tracer is the one of the provider installed last, otel.Tracer binds package variables to the first one.
+func tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// RegisterTracing traces every statement gorm runs on db as a child of the span of its context. Statements
+// run outside of a trace, such as those of a metrics scrape, are not traced.
+func RegisterTracing(db *gorm.DB) error {
+	system := semconv.DBSystemSqlite
+	if db.Dialector.Name() == DialectPostgres {
+		system = semconv.DBSystemPostgreSQL
+	}
+
+	start := func(operation string) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			ctx := tx.Statement.Context
+			if !trace.SpanFromContext(ctx).SpanContext().IsValid() {
+				return
+			}
+			ctx, span := tracer().Start(ctx, "sql "+operation,
+				trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(system))
+			tx.Statement.Context = ctx
+			tx.InstanceSet(tracingSpanKey, span)
+		}
+	}
+	end := func(tx *gorm.DB) {
+		value, ok := tx.InstanceGet(tracingSpanKey)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		span.SetAttributes(
+			semconv.DBStatementKey.String(tx.Statement.SQL.String()),
+			semconv.DBSQLTableKey.String(tx.Statement.Table),
+			attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+		)
+		if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(tx.Error)
+			span.SetStatus(codes.Error, tx.Error.Error())
+		}
+		span.End()
+	}
+
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("*").Register("tracing:before_create", start("create")),
+		callbacks.Create().After("*").Register("tracing:after_create", end),
+		callbacks.Query().Before("*").Register("tracing:before_query", start("query")),
+		callbacks.Query().After("*").Register("tracing:after_query", end),
+		callbacks.Update().Before("*").Register("tracing:before_update", start("update")),
+		callbacks.Update().After("*").Register("tracing:after_update", end),
+		callbacks.Delete().Before("*").Register("tracing:before_delete", start("delete")),
+		callbacks.Delete().After("*").Register("tracing:after_delete", end),
+		callbacks.Row().Before("*").Register("tracing:before_row", start("row")),
+		callbacks.Row().After("*").Register("tracing:after_row", end),
+		callbacks.Raw().Before("*").Register("tracing:before_raw", start("raw")),
+		callbacks.Raw().After("*").Register("tracing:after_raw", end),
+	} {
+		if err != nil {
+			return fmt.Errorf("err registering tracing callback:%w", err)
+		}
+	}
+	return nil
+}
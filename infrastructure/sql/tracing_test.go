@@ -0,0 +1,46 @@
+package sql_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/togglhire/backend-homework/infrastructure/sql"
+	"github.com/togglhire/backend-homework/infrastructure/sql/sqltest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRegisterTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	db := sqltest.Open(t)
+	if err := sql.RegisterTracing(db); err != nil {
+		t.Fatal(err)
+	}
+
+	_ = db.Exec("SELECT 1").Error
+	if ended := recorder.Ended(); len(ended) != 0 {
+		t.Errorf("statements outside of a trace were traced: %d spans", len(ended))
+	}
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	_ = db.WithContext(ctx).Exec("SELECT * FROM no_such_table").Error
+	parent.End()
+
+	ended := recorder.Ended()
+	if len(ended) != 2 {
+		t.Fatalf("spans recorded %d, expected the statement and its parent", len(ended))
+	}
+	statement := ended[0]
+	if statement.Name() != "sql raw" || statement.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("span %s of parent %s, expected sql raw of the parent", statement.Name(), statement.Parent().SpanID())
+	}
+	if statement.Status().Code != codes.Error {
+		t.Errorf("failed statement has status %v, expected an error", statement.Status())
+	}
+}
@@ -0,0 +1,226 @@
+package sql
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/togglhire/backend-homework/domain"
+	"gorm.io/gorm"
+)
+
+type QuestionTranslation struct {
+	OrganizationID string              `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int                 `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Locale         string              `db:"locale" gorm:"primaryKey"`
+	Body           string              `db:"body"`
+	Options        []OptionTranslation `gorm:"foreignKey:OrganizationID,QuestionID,Locale;references:OrganizationID,QuestionID,Locale"`
+}
+
+func (QuestionTranslation) TableName() string {
+	return "question_translation"
+}
+
+// OptionTranslation is bound to the position of the option, option ids change on every question update.
+type OptionTranslation struct {
+	OrganizationID string `db:"organization_id" gorm:"primaryKey"`
+	QuestionID     int    `db:"question_id" gorm:"primaryKey;autoIncrement:false"`
+	Locale         string `db:"locale" gorm:"primaryKey"`
+	Position       int    `db:"position" gorm:"primaryKey"`
+	Body           string `db:"body"`
+	Correct        bool   `db:"correct"`
+}
+
+func (OptionTranslation) TableName() string {
+	return "option_translation"
+}
+
+func (r Repository) GetTranslations(ctx context.Context, questionID int) (_ []domain.Translation, err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	var rows []QuestionTranslation
+	err = db.Preload("Options").Where("organization_id = ? AND question_id = ?", r.organization, questionID).Order("locale").Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("err query get translations:%w", err)
+	}
+
+	translations := make([]domain.Translation, 0, len(rows))
+	for _, row := range rows {
+		translations = append(translations, convertTranslationToDomain(row))
+	}
+	return translations, nil
+}
+
+func (r Repository) GetTranslationsByLocales(ctx context.Context, locales []string) (_ map[int][]domain.Translation, err error) {
+	defer func() { err = storageError(err) }()
+	translations := make(map[int][]domain.Translation)
+	if len(locales) == 0 {
+		return translations, nil
+	}
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+
+	var rows []QuestionTranslation
+	err = db.Preload("Options").Where("organization_id = ? AND locale IN ?", r.organization, locales).Find(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("err query get translations by locales:%w", err)
+	}
+
+	for _, row := range rows {
+		translations[row.QuestionID] = append(translations[row.QuestionID], convertTranslationToDomain(row))
+	}
+	return translations, nil
+}
+
+func (r Repository) SaveTranslation(ctx context.Context, questionID int, translation domain.Translation) (err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
+
+	if err := r.checkQuestionExists(tx, questionID); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	previous, found, err := r.getTranslation(tx, questionID, translation.Locale)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	var before interface{}
+	if found {
+		before = previous
+	}
+
+	if err := deleteTranslation(tx, questionID, r.organization, translation.Locale); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	dbTranslation := convertTranslationToDBModel(questionID, r.organization, translation)
+	if err := tx.Create(&dbTranslation).Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err sql exec adding translation:%w", err)
+	}
+
+	if err := r.recordAudit(tx, domain.AuditSaveTranslation, questionID, before, translation); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx save translation:%w", err)
+	}
+	return nil
+}
+
+func (r Repository) DeleteTranslation(ctx context.Context, questionID int, locale string) (err error) {
+	defer func() { err = storageError(err) }()
+	db, cancel := r.withContext(ctx)
+	defer cancel()
+	tx := db.Begin()
+
+	before, found, err := r.getTranslation(tx, questionID, locale)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	if !found {
+		_ = tx.Rollback()
+		return domain.ErrNoTranslationFound
+	}
+
+	if err := deleteTranslation(tx, questionID, r.organization, locale); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := r.recordAudit(tx, domain.AuditDeleteTranslation, questionID, before, nil); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("err commit trx delete translation:%w", err)
+	}
+	return nil
+}
+
+// getTranslation reads the translation of the question to locale, found is false when there is none.
+func (r Repository) getTranslation(tx *gorm.DB, questionID int, locale string) (_ domain.Translation, found bool, err error) {
+	var rows []QuestionTranslation
+	err = tx.Preload("Options").Where("organization_id = ? AND question_id = ? AND locale = ?", r.organization, questionID, locale).Find(&rows).Error
+	if err != nil {
+		return domain.Translation{}, false, fmt.Errorf("err query get translation:%w", err)
+	}
+	if len(rows) == 0 {
+		return domain.Translation{}, false, nil
+	}
+	return convertTranslationToDomain(rows[0]), true, nil
+}
+
+func deleteTranslation(tx *gorm.DB, questionID int, organization, locale string) error {
+	err := tx.Exec(`DELETE FROM option_translation where organization_id = ? AND question_id = ? AND locale = ?`, organization, questionID, locale).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting option translations:%w", err)
+	}
+	err = tx.Exec(`DELETE FROM question_translation where organization_id = ? AND question_id = ? AND locale = ?`, organization, questionID, locale).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting question translation:%w", err)
+	}
+	return nil
+}
+
+// pruneTranslations deletes the translations that no longer match the question, keeping them would mean serving
+// a different set of answers depending on the language.
+func pruneTranslations(tx *gorm.DB, question domain.Question, organization string) error {
+	var rows []QuestionTranslation
+	err := tx.Preload("Options").Where("organization_id = ? AND question_id = ?", organization, question.ID).Find(&rows).Error
+	if err != nil {
+		return fmt.Errorf("err query translations of question:%w", err)
+	}
+	for _, row := range rows {
+		if convertTranslationToDomain(row).Matches(question) {
+			continue
+		}
+		if err := deleteTranslation(tx, question.ID, organization, row.Locale); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteQuestionTranslations(tx *gorm.DB, questionID int, organization string) error {
+	err := tx.Exec(`DELETE FROM option_translation where organization_id = ? AND question_id = ?`, organization, questionID).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting option translations:%w", err)
+	}
+	err = tx.Exec(`DELETE FROM question_translation where organization_id = ? AND question_id = ?`, organization, questionID).Error
+	if err != nil {
+		return fmt.Errorf("err sql exec deleting question translations:%w", err)
+	}
+	return nil
+}
+
+func convertTranslationToDomain(row QuestionTranslation) domain.Translation {
+	sort.Slice(row.Options, func(i, j int) bool { return row.Options[i].Position < row.Options[j].Position })
+	options := make([]domain.OptionTranslation, 0, len(row.Options))
+	for _, opt := range row.Options {
+		options = append(options, domain.OptionTranslation{Body: opt.Body, Correct: opt.Correct})
+	}
+	return domain.Translation{Locale: row.Locale, Body: row.Body, Options: options}
+}
+
+func convertTranslationToDBModel(questionID int, organization string, translation domain.Translation) QuestionTranslation {
+	options := make([]OptionTranslation, 0, len(translation.Options))
+	for i, opt := range translation.Options {
+		options = append(options, OptionTranslation{OrganizationID: organization, QuestionID: questionID, Locale: translation.Locale, Position: i, Body: opt.Body, Correct: opt.Correct})
+	}
+	return QuestionTranslation{OrganizationID: organization, QuestionID: questionID, Locale: translation.Locale, Body: translation.Body,
+		Options: options}
+}
@@ -0,0 +1,82 @@
+// Package telemetry sets up where the traces of the service go.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// spans still queued are given this long to be exported on close
+const TRACING_SHUTDOWN_TIMEOUT = 5 * time.Second
+
+type TracingConfig struct {
+	// Endpoint is the base url of an OTLP/HTTP collector such as http://localhost:4318, spans are recorded but
+	// not exported without one.
+	Endpoint    string
+	ServiceName string
+	// SampleRatio is the share of traces started here that are kept, traces started by a caller follow its choice.
+	SampleRatio float64
+}
+
+// Tracing is the tracer provider every package traces with once installed.
+type Tracing struct {
+	provider *sdktrace.TracerProvider
+}
+
+// NewTracing exports spans in batches to the collector of the config.
+func NewTracing(ctx context.Context, cfg TracingConfig) (Tracing, error) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceNameKey.String(cfg.ServiceName))),
+	}
+	if cfg.Endpoint != "" {
+		exporter, err := otlpExporter(ctx, cfg.Endpoint)
+		if err != nil {
+			return Tracing{}, err
+		}
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+	return Tracing{provider: sdktrace.NewTracerProvider(options...)}, nil
+}
+
+func otlpExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("err parsing otlp endpoint %q, expected a url such as http://localhost:4318", endpoint)
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host)}
+	if u.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	if path := strings.TrimSuffix(u.Path, "/"); path != "" {
+		options = append(options, otlptracehttp.WithURLPath(path+"/v1/traces"))
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("err creating otlp exporter:%w", err)
+	}
+	return exporter, nil
+}
+
+// Install makes the provider the one of otel.Tracer and reads and writes W3C trace context and baggage headers.
+func (t Tracing) Install() {
+	otel.SetTracerProvider(t.provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Close exports the spans still queued.
+func (t Tracing) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), TRACING_SHUTDOWN_TIMEOUT)
+	defer cancel()
+	return t.provider.Shutdown(ctx)
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"github.com/togglhire/backend-homework/usecase/irt"
+)
+
+const CALIBRATION_ITERATIONS = 200
+
+type AdaptiveConfig struct {
+	// MinResponses an item needs before it is calibrated and used in adaptive sessions
+	MinResponses int
+	// MaxItems and StandardErrorThreshold are the stop rules used when a session does not set its own
+	MaxItems               int
+	StandardErrorThreshold float64
+}
+
+// Adaptive grades answers through responses, so they count like any other, and reads the stored ones from
+// responseRepo.
+type Adaptive struct {
+	questions    domain.QuestionRepository
+	responses    Responses
+	responseRepo domain.ResponseRepository
+	repo         domain.AdaptiveRepository
+	cfg          AdaptiveConfig
+}
+
+func NewAdaptive(questionRepository domain.QuestionRepository, responses Responses, responseRepository domain.ResponseRepository, adaptiveRepository domain.AdaptiveRepository, cfg AdaptiveConfig) Adaptive {
+	return Adaptive{questions: questionRepository, responses: responses, responseRepo: responseRepository, repo: adaptiveRepository, cfg: cfg}
+}
+
+// Calibrate estimates the item parameters from every stored response and replaces the previous ones, unless
+// ctx is done by then.
+func (a Adaptive) Calibrate(ctx context.Context) (_ []domain.ItemParameters, err error) {
+	ctx, end := startSpan(ctx, "Adaptive.Calibrate")
+	defer end(&err)
+
+	responses, err := a.responseRepo.GetResponses()
+	if err != nil {
+		return nil, fmt.Errorf("err getting responses:%w", err)
+	}
+
+	answers := make([]irt.Answer, 0, len(responses))
+	counts := make(map[int]int)
+	for _, response := range responses {
+		answers = append(answers, irt.Answer{Person: response.Candidate, Item: response.QuestionID, Correct: response.Correct})
+		counts[response.QuestionID]++
+	}
+
+	items := irt.Calibrate(answers, a.cfg.MinResponses, CALIBRATION_ITERATIONS)
+	now := time.Now().UTC()
+	parameters := make([]domain.ItemParameters, 0, len(items))
+	for id, item := range items {
+		parameters = append(parameters, domain.ItemParameters{QuestionID: id, Discrimination: item.A, Difficulty: item.B, Responses: counts[id], CalibratedAt: now})
+	}
+
+	// fitting takes a while, parameters of a calibration given up on are not saved
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := a.repo.SaveItemParameters(parameters); err != nil {
+		return nil, fmt.Errorf("err saving item parameters:%w", err)
+	}
+	return a.repo.GetItemParameters()
+}
+
+// RunCalibration recalibrates the items of every organization periodically until the context is done.
+func RunCalibration(ctx context.Context, interval time.Duration, organizations domain.OrganizationRepository, adaptive func(organization string) Adaptive) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := organizations.GetOrganizations()
+			if err != nil {
+				slog.ErrorContext(ctx, "err getting organizations to calibrate", "err", err)
+				continue
+			}
+			for _, id := range ids {
+				parameters, err := adaptive(id).Calibrate(ctx)
+				if err != nil {
+					slog.ErrorContext(ctx, "err calibrating items", "organization", id, "err", err)
+					continue
+				}
+				slog.InfoContext(ctx, "irt: calibrated items", "organization", id, "items", len(parameters))
+			}
+		}
+	}
+}
+
+func (a Adaptive) Start(ctx context.Context, session domain.AdaptiveSession) (_ domain.AdaptiveSession, err error) {
+	ctx, end := startSpan(ctx, "Adaptive.Start")
+	defer end(&err)
+
+	id, err := newID()
+	if err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	session.ID = id
+	session.Items = nil
+	session.Finished = false
+	session.CreatedAt = time.Now().UTC()
+	if session.MaxItems == 0 {
+		session.MaxItems = a.cfg.MaxItems
+	}
+	if session.StandardErrorThreshold == 0 {
+		session.StandardErrorThreshold = a.cfg.StandardErrorThreshold
+	}
+	session.Ability, session.StandardError = irt.EstimateAbility(nil, nil)
+
+	parameters, err := a.repo.GetItemParameters()
+	if err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err getting item parameters:%w", err)
+	}
+	if len(parameters) == 0 {
+		return domain.AdaptiveSession{}, domain.ErrNoCalibratedItems
+	}
+
+	if err := a.advance(&session, parameters); err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	if err := a.repo.AddSession(session); err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err adding adaptive session:%w", err)
+	}
+	return a.withNext(ctx, session)
+}
+
+// Get returns the session of the candidate, sessions of other candidates are not found.
+func (a Adaptive) Get(ctx context.Context, id string, candidate string) (_ domain.AdaptiveSession, err error) {
+	ctx, end := startSpan(ctx, "Adaptive.Get")
+	defer end(&err)
+
+	session, err := a.session(id, candidate)
+	if err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	return a.withNext(ctx, session)
+}
+
+// Answer records the response to the question the session asked and moves on to the next one, or finishes
+// the session when a stop rule is met. Only the candidate of the session answers it.
+func (a Adaptive) Answer(ctx context.Context, id string, response domain.Response) (_ domain.AdaptiveSession, err error) {
+	ctx, end := startSpan(ctx, "Adaptive.Answer")
+	defer end(&err)
+
+	session, err := a.session(id, response.Candidate)
+	if err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	if session.Finished {
+		return domain.AdaptiveSession{}, domain.ErrSessionFinished
+	}
+	if session.NextQuestionID == nil || *session.NextQuestionID != response.QuestionID {
+		return domain.AdaptiveSession{}, domain.ErrUnexpectedQuestion
+	}
+
+	_, response, err = a.responses.grade(ctx, response)
+	if err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	session.Items = append(session.Items, domain.AdaptiveItem{QuestionID: response.QuestionID, Correct: response.Correct})
+
+	parameters, err := a.repo.GetItemParameters()
+	if err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err getting item parameters:%w", err)
+	}
+	if err := a.advance(&session, parameters); err != nil {
+		return domain.AdaptiveSession{}, err
+	}
+	// the response is stored along with the session, a failed update leaves the question to be answered again
+	if _, err := a.repo.AnswerSession(session, response); err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err answering adaptive session:%w", err)
+	}
+	return a.withNext(ctx, session)
+}
+
+// session reads the session of the candidate, telling sessions of others apart would reveal they exist.
+func (a Adaptive) session(id string, candidate string) (domain.AdaptiveSession, error) {
+	session, err := a.repo.GetSession(id)
+	if err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err getting adaptive session:%w", err)
+	}
+	if session.Candidate != candidate {
+		return domain.AdaptiveSession{}, domain.ErrNoSessionFound
+	}
+	return session, nil
+}
+
+// advance estimates the ability from the answered items and picks the next question, the most informative
+// at that ability among the ones the candidate never answered.
+func (a Adaptive) advance(session *domain.AdaptiveSession, parameters []domain.ItemParameters) error {
+	byQuestion := make(map[int]irt.Item, len(parameters))
+	for _, p := range parameters {
+		byQuestion[p.QuestionID] = irt.Item{A: p.Discrimination, B: p.Difficulty}
+	}
+
+	var items []irt.Item
+	var answers []bool
+	for _, answered := range session.Items {
+		if item, ok := byQuestion[answered.QuestionID]; ok {
+			items = append(items, item)
+			answers = append(answers, answered.Correct)
+		}
+	}
+	session.Ability, session.StandardError = irt.EstimateAbility(items, answers)
+	session.NextQuestionID = nil
+
+	if session.StandardError < session.StandardErrorThreshold || len(session.Items) >= session.MaxItems {
+		session.Finished = true
+		return nil
+	}
+
+	answered, err := a.responseRepo.GetCandidateResponses(session.Candidate)
+	if err != nil {
+		return fmt.Errorf("err getting candidate responses:%w", err)
+	}
+	// the items of the session are stored along with it, the last one is not among the responses yet
+	seen := make(map[int]bool, len(answered)+len(session.Items))
+	for _, response := range answered {
+		seen[response.QuestionID] = true
+	}
+	for _, item := range session.Items {
+		seen[item.QuestionID] = true
+	}
+
+	var candidates []irt.Item
+	var ids []int
+	for _, p := range parameters {
+		if !seen[p.QuestionID] {
+			candidates = append(candidates, byQuestion[p.QuestionID])
+			ids = append(ids, p.QuestionID)
+		}
+	}
+	next := irt.MostInformative(session.Ability, candidates)
+	if next < 0 {
+		session.Finished = true
+		return nil
+	}
+	session.NextQuestionID = &ids[next]
+	return nil
+}
+
+func (a Adaptive) withNext(ctx context.Context, session domain.AdaptiveSession) (domain.AdaptiveSession, error) {
+	if session.NextQuestionID == nil {
+		return session, nil
+	}
+	question, err := a.questions.Get(ctx, *session.NextQuestionID)
+	if err != nil {
+		return domain.AdaptiveSession{}, fmt.Errorf("err getting next question:%w", err)
+	}
+	next := domain.NewCandidateQuestion(question)
+	session.Next = &next
+	return session, nil
+}
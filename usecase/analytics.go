@@ -0,0 +1,167 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/togglhire/backend-homework/domain"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type Analytics struct {
+	questions domain.QuestionRepository
+	repo      domain.AnalyticsRepository
+}
+
+func NewAnalytics(questionRepository domain.QuestionRepository, analyticsRepository domain.AnalyticsRepository) Analytics {
+	return Analytics{questions: questionRepository, repo: analyticsRepository}
+}
+
+func (a Analytics) QuestionStats(ctx context.Context, id int) (_ domain.QuestionStats, err error) {
+	ctx, end := startSpan(ctx, "Analytics.QuestionStats", attribute.Int("question.id", id))
+	defer end(&err)
+
+	question, err := a.questions.Get(ctx, id)
+	if err != nil {
+		return domain.QuestionStats{}, fmt.Errorf("err getting question:%w", err)
+	}
+
+	stats, err := a.stats([]domain.Question{question})
+	if err != nil {
+		return domain.QuestionStats{}, err
+	}
+	return stats[0], nil
+}
+
+func (a Analytics) Report(ctx context.Context) (_ domain.LibraryReport, err error) {
+	ctx, end := startSpan(ctx, "Analytics.Report")
+	defer end(&err)
+
+	questions, err := a.questions.GetAll(ctx)
+	if err != nil {
+		return domain.LibraryReport{}, fmt.Errorf("err getting questions:%w", err)
+	}
+
+	stats, err := a.stats(questions)
+	if err != nil {
+		return domain.LibraryReport{}, err
+	}
+
+	report := domain.LibraryReport{Questions: len(questions), Items: stats}
+	sum, answered := 0.0, 0
+	for _, item := range stats {
+		report.Responses += item.Responses
+		if item.PValue != nil {
+			sum += *item.PValue
+			answered++
+		}
+	}
+	if answered > 0 {
+		mean := sum / float64(answered)
+		report.MeanPValue = &mean
+	}
+	return report, nil
+}
+
+func (a Analytics) stats(questions []domain.Question) ([]domain.QuestionStats, error) {
+	var ids []int
+	if len(questions) == 1 {
+		ids = []int{questions[0].ID}
+	}
+
+	counts, err := a.repo.GetItemCounts(ids)
+	if err != nil {
+		return nil, fmt.Errorf("err getting item counts:%w", err)
+	}
+	scores, err := a.repo.GetItemScores(ids)
+	if err != nil {
+		return nil, fmt.Errorf("err getting item scores:%w", err)
+	}
+
+	countsByQuestion := make(map[int]domain.ItemCounts, len(counts))
+	for _, c := range counts {
+		countsByQuestion[c.QuestionID] = c
+	}
+	scoresByQuestion := make(map[int][]domain.ItemScore)
+	for _, s := range scores {
+		scoresByQuestion[s.QuestionID] = append(scoresByQuestion[s.QuestionID], s)
+	}
+
+	stats := make([]domain.QuestionStats, 0, len(questions))
+	for _, question := range questions {
+		stats = append(stats, itemStats(question, countsByQuestion[question.ID], scoresByQuestion[question.ID]))
+	}
+	return stats, nil
+}
+
+func itemStats(question domain.Question, counts domain.ItemCounts, scores []domain.ItemScore) domain.QuestionStats {
+	stats := domain.QuestionStats{QuestionID: question.ID, Responses: counts.Responses, Correct: counts.Correct}
+	if counts.Responses > 0 {
+		p := float64(counts.Correct) / float64(counts.Responses)
+		stats.PValue = &p
+	}
+	stats.Discrimination = PointBiserial(scores)
+
+	for i, opt := range question.Options {
+		option := domain.OptionStats{Position: i, Body: opt.Body, Correct: opt.Correct, Picks: counts.Picks[i]}
+		if counts.Responses > 0 {
+			option.PickRate = float64(option.Picks) / float64(counts.Responses)
+			option.NeverChosen = option.Picks == 0
+		}
+		stats.Options = append(stats.Options, option)
+	}
+	return stats
+}
+
+// PointBiserial correlates getting the item right with the rest score of the candidates, the proportion of
+// their other responses that were correct. Candidates answer different sets of questions so proportions
+// are used instead of raw totals, and the item itself is left out to not inflate the correlation.
+// It returns nil when there is not enough spread in the data to compute it.
+func PointBiserial(scores []domain.ItemScore) *float64 {
+	var right, wrong, all []float64
+	for _, s := range scores {
+		if s.CandidateResponses < 2 {
+			continue
+		}
+		item := 0
+		if s.Correct {
+			item = 1
+		}
+		rest := float64(s.CandidateCorrect-item) / float64(s.CandidateResponses-1)
+		all = append(all, rest)
+		if s.Correct {
+			right = append(right, rest)
+		} else {
+			wrong = append(wrong, rest)
+		}
+	}
+	if len(right) == 0 || len(wrong) == 0 {
+		return nil
+	}
+
+	sd := stdDev(all)
+	if sd == 0 {
+		return nil
+	}
+	p := float64(len(right)) / float64(len(all))
+	r := (mean(right) - mean(wrong)) / sd * math.Sqrt(p*(1-p))
+	return &r
+}
+
+func mean(values []float64) float64 {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stdDev(values []float64) float64 {
+	m := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// API_KEY_PREFIX marks the secrets of api keys, so they can be told apart from other bearer tokens.
+const API_KEY_PREFIX = "qk_"
+
+type APIKeys struct {
+	repo domain.APIKeyRepository
+	now  func() time.Time
+}
+
+func NewAPIKeys(apiKeyRepository domain.APIKeyRepository) APIKeys {
+	return APIKeys{repo: apiKeyRepository, now: time.Now}
+}
+
+// Create stores a new key and returns it with its secret, the only time the secret is available.
+func (a APIKeys) Create(key domain.APIKey) (domain.APIKey, string, error) {
+	id, err := newID()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	secret, err := newID()
+	if err != nil {
+		return domain.APIKey{}, "", err
+	}
+	plain := API_KEY_PREFIX + secret
+
+	key.ID = id
+	key.Prefix = plain[:len(API_KEY_PREFIX)+6]
+	key.CreatedAt = a.now().UTC()
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	if err := a.repo.AddAPIKey(key, HashAPIKey(plain)); err != nil {
+		return domain.APIKey{}, "", fmt.Errorf("err adding api key:%w", err)
+	}
+	return key, plain, nil
+}
+
+func (a APIKeys) List() ([]domain.APIKey, error) {
+	return a.repo.GetAPIKeys()
+}
+
+func (a APIKeys) Revoke(id string) error {
+	return a.repo.RevokeAPIKey(id, a.now().UTC())
+}
+
+// Authenticate finds the key of a secret and records its use, revoked and expired keys are refused.
+func (a APIKeys) Authenticate(secret string) (domain.APIKey, error) {
+	if !IsAPIKey(secret) {
+		return domain.APIKey{}, domain.ErrNoAPIKeyFound
+	}
+	key, err := a.repo.FindAPIKey(HashAPIKey(secret))
+	if err != nil {
+		return domain.APIKey{}, err
+	}
+
+	now := a.now().UTC()
+	if key.RevokedAt != nil {
+		return domain.APIKey{}, domain.ErrAPIKeyRevoked
+	}
+	if key.Expired(now) {
+		return domain.APIKey{}, domain.ErrAPIKeyExpired
+	}
+
+	if err := a.repo.TouchAPIKey(key.ID, now); err != nil {
+		// a stale last used time is not worth refusing the request
+		slog.Warn("err touching api key", "api_key", key.ID, "err", err)
+	} else {
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+// IsAPIKey tells whether a credential has the shape of an api key secret.
+func IsAPIKey(secret string) bool {
+	return strings.HasPrefix(secret, API_KEY_PREFIX)
+}
+
+// HashAPIKey is what gets stored instead of the secret. The secrets are random, a plain sha256 is enough
+// and keeps the lookup by hash possible.
+func HashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+type Attachments struct {
+	repo    domain.AttachmentRepository
+	store   domain.BlobStore
+	maxSize int64
+}
+
+func NewAttachments(attachmentRepository domain.AttachmentRepository, store domain.BlobStore, maxSize int64) Attachments {
+	return Attachments{repo: attachmentRepository, store: store, maxSize: maxSize}
+}
+
+// Upload validates and stores a new attachment, the content type is sniffed from the content itself
+// instead of trusting the one declared by the client.
+func (a Attachments) Upload(filename string, r io.Reader) (domain.Attachment, error) {
+	content, err := io.ReadAll(io.LimitReader(r, a.maxSize+1))
+	if err != nil {
+		return domain.Attachment{}, fmt.Errorf("err reading attachment:%w", err)
+	}
+	if int64(len(content)) > a.maxSize {
+		return domain.Attachment{}, domain.ErrAttachmentTooLarge
+	}
+
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(content))
+	if err != nil || !allowedMediaType(mediaType) {
+		return domain.Attachment{}, domain.ErrAttachmentContentType
+	}
+
+	id, err := newID()
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	attachment := domain.Attachment{
+		ID:          id,
+		Filename:    filename,
+		ContentType: mediaType,
+		Size:        int64(len(content)),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if err := a.store.Put(id, bytes.NewReader(content)); err != nil {
+		return domain.Attachment{}, fmt.Errorf("err storing attachment blob:%w", err)
+	}
+	if err := a.repo.AddAttachment(attachment); err != nil {
+		_ = a.store.Delete(id)
+		return domain.Attachment{}, fmt.Errorf("err adding attachment:%w", err)
+	}
+	return attachment, nil
+}
+
+// Open returns the attachment metadata along with its content, the caller must close the content.
+func (a Attachments) Open(id string) (domain.Attachment, io.ReadCloser, error) {
+	attachment, err := a.repo.GetAttachment(id)
+	if err != nil {
+		return domain.Attachment{}, nil, fmt.Errorf("err getting attachment:%w", err)
+	}
+	content, err := a.store.Get(id)
+	if err != nil {
+		return domain.Attachment{}, nil, fmt.Errorf("err getting attachment blob:%w", err)
+	}
+	return attachment, content, nil
+}
+
+// Release drops the given attachments, and their blobs, when nothing references them anymore.
+func (a Attachments) Release(ids []string) error {
+	orphans, err := a.repo.DeleteUnreferencedAttachments(ids)
+	if err != nil {
+		return fmt.Errorf("err deleting unreferenced attachments:%w", err)
+	}
+	a.deleteBlobs(orphans)
+	return nil
+}
+
+// Sweep drops the attachments uploaded more than ttl ago that nothing references, and their blobs, returning
+// how many went.
+func (a Attachments) Sweep(ttl time.Duration) (int, error) {
+	orphans, err := a.repo.DeleteStaleAttachments(time.Now().UTC().Add(-ttl))
+	if err != nil {
+		return 0, fmt.Errorf("err deleting stale attachments:%w", err)
+	}
+	a.deleteBlobs(orphans)
+	return len(orphans), nil
+}
+
+// deleteBlobs removes the blobs of deleted attachments, a blob left behind is only wasted space.
+func (a Attachments) deleteBlobs(ids []string) {
+	for _, id := range ids {
+		if err := a.store.Delete(id); err != nil {
+			slog.Error("err deleting orphan blob", "attachment", id, "err", err)
+		}
+	}
+}
+
+// RunAttachmentSweep sweeps the attachments of every organization periodically until the context is done,
+// see Attachments.Sweep.
+func RunAttachmentSweep(ctx context.Context, interval, ttl time.Duration, organizations domain.OrganizationRepository, attachments func(organization string) Attachments) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ids, err := organizations.GetOrganizations()
+			if err != nil {
+				slog.ErrorContext(ctx, "err getting organizations to sweep attachments of", "err", err)
+				continue
+			}
+			for _, id := range ids {
+				swept, err := attachments(id).Sweep(ttl)
+				if err != nil {
+					slog.ErrorContext(ctx, "err sweeping attachments", "organization", id, "err", err)
+					continue
+				}
+				if swept > 0 {
+					slog.InfoContext(ctx, "attachments: swept unreferenced attachments", "organization", id, "attachments", swept)
+				}
+			}
+		}
+	}
+}
+
+func allowedMediaType(mediaType string) bool {
+	for _, allowed := range domain.AllowedAttachmentMediaTypes {
+		if mediaType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("err generating id:%w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+const (
+	AUDIT_DEFAULT_LIMIT = 50
+	AUDIT_MAX_LIMIT     = 200
+)
+
+type Audit struct {
+	repo domain.AuditRepository
+}
+
+func NewAudit(auditRepository domain.AuditRepository) Audit {
+	return Audit{repo: auditRepository}
+}
+
+// List returns a page of entries newest first, one more entry than asked is read to know if a page follows.
+func (a Audit) List(ctx context.Context, filter domain.AuditFilter) (_ domain.AuditPage, err error) {
+	ctx, end := startSpan(ctx, "Audit.List")
+	defer end(&err)
+
+	if filter.Limit <= 0 {
+		filter.Limit = AUDIT_DEFAULT_LIMIT
+	}
+	if filter.Limit > AUDIT_MAX_LIMIT {
+		filter.Limit = AUDIT_MAX_LIMIT
+	}
+	limit := filter.Limit
+	filter.Limit++
+
+	entries, err := a.repo.GetAuditEntries(ctx, filter)
+	if err != nil {
+		return domain.AuditPage{}, fmt.Errorf("err getting audit entries:%w", err)
+	}
+
+	page := domain.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		next := page.Entries[limit-1].ID
+		page.NextCursor = &next
+	}
+	return page, nil
+}
+
+// Verify walks the chain of the organization and reports the first entry that does not hold.
+func (a Audit) Verify(ctx context.Context) (_ domain.AuditVerification, err error) {
+	ctx, end := startSpan(ctx, "Audit.Verify")
+	defer end(&err)
+
+	chain, err := a.repo.GetAuditChain(ctx)
+	if err != nil {
+		return domain.AuditVerification{}, fmt.Errorf("err getting audit chain:%w", err)
+	}
+
+	verification := domain.AuditVerification{Entries: len(chain), Valid: true}
+	prev := ""
+	for _, entry := range chain {
+		if entry.PrevHash != prev || entry.ComputeHash() != entry.Hash {
+			id := entry.ID
+			verification.Valid = false
+			verification.BrokenAt = &id
+			break
+		}
+		prev = entry.Hash
+	}
+	return verification, nil
+}
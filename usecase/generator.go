@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+type Generator struct {
+	repo domain.QuestionRepository
+}
+
+func NewGenerator(questionRepository domain.QuestionRepository) Generator {
+	return Generator{repo: questionRepository}
+}
+
+// pool is a group of interchangeable candidate questions, each pool is filled independently.
+type pool struct {
+	candidates []domain.Question
+	want       int
+	chosen     []domain.Question
+}
+
+// Generate picks questions at random under the spec constraints, the same seed over the same library
+// always gives the same test.
+func (g Generator) Generate(ctx context.Context, spec domain.TestSpec) (_ domain.Test, err error) {
+	ctx, end := startSpan(ctx, "Generator.Generate")
+	defer end(&err)
+
+	seed := time.Now().UnixNano()
+	if spec.Seed != nil {
+		seed = *spec.Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	candidates, err := g.repo.Find(ctx, domain.QuestionFilter{Tags: spec.Tags, Sort: []domain.SortField{{Field: "id"}}})
+	if err != nil {
+		return domain.Test{}, fmt.Errorf("err finding candidate questions:%w", err)
+	}
+
+	pools, err := buildPools(spec, candidates)
+	if err != nil {
+		return domain.Test{}, err
+	}
+
+	if spec.MaxTotalSeconds > 0 && minimalSeconds(pools) > spec.MaxTotalSeconds {
+		return domain.Test{}, fmt.Errorf("%w, the shortest test takes %d seconds", domain.ErrUnsatisfiableConstraints, minimalSeconds(pools))
+	}
+
+	total := 0
+	for _, p := range pools {
+		rng.Shuffle(len(p.candidates), func(i, j int) { p.candidates[i], p.candidates[j] = p.candidates[j], p.candidates[i] })
+		p.chosen = p.candidates[:p.want]
+		total += seconds(p.chosen)
+	}
+	if spec.MaxTotalSeconds > 0 {
+		for total > spec.MaxTotalSeconds {
+			total -= shortenOnce(pools)
+		}
+	}
+
+	test := domain.Test{Seed: seed, Questions: make([]domain.Question, 0, spec.Count)}
+	for _, p := range pools {
+		test.Questions = append(test.Questions, p.chosen...)
+	}
+	rng.Shuffle(len(test.Questions), func(i, j int) { test.Questions[i], test.Questions[j] = test.Questions[j], test.Questions[i] })
+	for _, question := range test.Questions {
+		test.TotalEstimatedSeconds += question.EstimatedSeconds
+		test.TotalPoints += question.Points
+	}
+	return test, nil
+}
+
+func buildPools(spec domain.TestSpec, candidates []domain.Question) ([]*pool, error) {
+	byDifficulty := make(map[domain.Difficulty]*pool)
+	rest := &pool{want: spec.Count}
+	for _, difficulty := range []domain.Difficulty{domain.DifficultyEasy, domain.DifficultyMedium, domain.DifficultyHard} {
+		if want, ok := spec.Difficulties[difficulty]; ok {
+			byDifficulty[difficulty] = &pool{want: want}
+			rest.want -= want
+		}
+	}
+	if rest.want < 0 {
+		return nil, fmt.Errorf("%w, difficulties ask for more than %d questions", domain.ErrUnsatisfiableConstraints, spec.Count)
+	}
+
+	for _, question := range candidates {
+		if p, ok := byDifficulty[question.Difficulty]; ok {
+			p.candidates = append(p.candidates, question)
+		} else {
+			rest.candidates = append(rest.candidates, question)
+		}
+	}
+
+	pools := make([]*pool, 0, len(byDifficulty)+1)
+	for _, difficulty := range []domain.Difficulty{domain.DifficultyEasy, domain.DifficultyMedium, domain.DifficultyHard} {
+		if p, ok := byDifficulty[difficulty]; ok {
+			pools = append(pools, p)
+		}
+	}
+	pools = append(pools, rest)
+
+	for _, p := range pools {
+		if len(p.candidates) < p.want {
+			return nil, fmt.Errorf("%w, wanted %d but only %d available", domain.ErrUnsatisfiableConstraints, p.want, len(p.candidates))
+		}
+	}
+	return pools, nil
+}
+
+// minimalSeconds is the time of the shortest possible test, taking the shortest questions of every pool.
+func minimalSeconds(pools []*pool) int {
+	total := 0
+	for _, p := range pools {
+		times := make([]int, 0, len(p.candidates))
+		for _, question := range p.candidates {
+			times = append(times, question.EstimatedSeconds)
+		}
+		sort.Ints(times)
+		for _, t := range times[:p.want] {
+			total += t
+		}
+	}
+	return total
+}
+
+// shortenOnce swaps the longest chosen question for the shortest unchosen one of the same pool, returning
+// the seconds saved. It must only be called when the test is not yet the shortest possible one.
+func shortenOnce(pools []*pool) int {
+	var best *pool
+	bestSaving, bestChosen, bestUnchosen := 0, 0, 0
+	for _, p := range pools {
+		longest, shortest := -1, -1
+		for i := 0; i < len(p.candidates); i++ {
+			if i < p.want {
+				if longest < 0 || p.candidates[i].EstimatedSeconds > p.candidates[longest].EstimatedSeconds {
+					longest = i
+				}
+			} else if shortest < 0 || p.candidates[i].EstimatedSeconds < p.candidates[shortest].EstimatedSeconds {
+				shortest = i
+			}
+		}
+		if longest < 0 || shortest < 0 {
+			continue
+		}
+		if saving := p.candidates[longest].EstimatedSeconds - p.candidates[shortest].EstimatedSeconds; saving > bestSaving {
+			best, bestSaving, bestChosen, bestUnchosen = p, saving, longest, shortest
+		}
+	}
+	best.candidates[bestChosen], best.candidates[bestUnchosen] = best.candidates[bestUnchosen], best.candidates[bestChosen]
+	best.chosen = best.candidates[:best.want]
+	return bestSaving
+}
+
+func seconds(questions []domain.Question) int {
+	total := 0
+	for _, question := range questions {
+		total += question.EstimatedSeconds
+	}
+	return total
+}
@@ -0,0 +1,223 @@
+// Package irt implements the two parameter logistic model of Item Response Theory, the calibration of item
+// parameters from responses and the estimation of abilities used by adaptive tests.
+package irt
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	minDiscrimination = 0.2
+	maxDiscrimination = 4
+	maxDifficulty     = 4
+	// ability scale covered by the quadrature, the latent trait is assumed to follow a standard normal
+	quadratureRange  = 4
+	quadraturePoints = 41
+)
+
+// Item holds the 2PL parameters of a question, A is the discrimination and B the difficulty.
+type Item struct {
+	A float64
+	B float64
+}
+
+// Probability of answering the item right at the given ability.
+func (i Item) Probability(theta float64) float64 {
+	return 1 / (1 + math.Exp(-i.A*(theta-i.B)))
+}
+
+// Information is the Fisher information the item gives about the ability, the higher the more an answer to it
+// narrows down the ability estimate.
+func (i Item) Information(theta float64) float64 {
+	p := i.Probability(theta)
+	return i.A * i.A * p * (1 - p)
+}
+
+// Answer is a scored response of a person to an item.
+type Answer struct {
+	Person  string
+	Item    int
+	Correct bool
+}
+
+type quadrature struct {
+	points  []float64
+	weights []float64
+}
+
+func newQuadrature() quadrature {
+	q := quadrature{points: make([]float64, quadraturePoints), weights: make([]float64, quadraturePoints)}
+	step := 2 * quadratureRange / float64(quadraturePoints-1)
+	total := 0.0
+	for k := range q.points {
+		q.points[k] = -quadratureRange + float64(k)*step
+		q.weights[k] = math.Exp(-q.points[k] * q.points[k] / 2)
+		total += q.weights[k]
+	}
+	for k := range q.weights {
+		q.weights[k] /= total
+	}
+	return q
+}
+
+// EstimateAbility returns the expected a posteriori ability given the answers to the items, along with its
+// standard error. A standard normal prior keeps the estimate finite when all answers are right or wrong.
+func EstimateAbility(items []Item, answers []bool) (float64, float64) {
+	q := newQuadrature()
+	posterior := make([]float64, len(q.points))
+	total := 0.0
+	for k, theta := range q.points {
+		likelihood := q.weights[k]
+		for i, item := range items {
+			p := item.Probability(theta)
+			if answers[i] {
+				likelihood *= p
+			} else {
+				likelihood *= 1 - p
+			}
+		}
+		posterior[k] = likelihood
+		total += likelihood
+	}
+
+	mean := 0.0
+	for k, theta := range q.points {
+		posterior[k] /= total
+		mean += posterior[k] * theta
+	}
+	variance := 0.0
+	for k, theta := range q.points {
+		variance += posterior[k] * (theta - mean) * (theta - mean)
+	}
+	return mean, math.Sqrt(variance)
+}
+
+// MostInformative returns the index of the candidate item giving the most information at the ability,
+// -1 when there are no candidates.
+func MostInformative(theta float64, candidates []Item) int {
+	best, bestInformation := -1, 0.0
+	for i, item := range candidates {
+		if information := item.Information(theta); best < 0 || information > bestInformation {
+			best, bestInformation = i, information
+		}
+	}
+	return best
+}
+
+// Calibrate estimates the parameters of every item by marginal maximum likelihood, using the EM algorithm
+// of Bock and Aitkin over a fixed quadrature. Persons do not need to answer every item. Items answered
+// less than minResponses times are left out of the result.
+func Calibrate(answers []Answer, minResponses int, iterations int) map[int]Item {
+	byItem := make(map[int]int)
+	for _, a := range answers {
+		byItem[a.Item]++
+	}
+	items := make(map[int]Item)
+	for id, n := range byItem {
+		if n >= minResponses {
+			items[id] = Item{A: 1, B: 0}
+		}
+	}
+	if len(items) == 0 {
+		return items
+	}
+
+	byPerson := make(map[string][]Answer)
+	var persons []string
+	for _, a := range answers {
+		if _, ok := items[a.Item]; !ok {
+			continue
+		}
+		if _, ok := byPerson[a.Person]; !ok {
+			persons = append(persons, a.Person)
+		}
+		byPerson[a.Person] = append(byPerson[a.Person], a)
+	}
+	sort.Strings(persons)
+
+	q := newQuadrature()
+	for iteration := 0; iteration < iterations; iteration++ {
+		expected := make(map[int]*[2][quadraturePoints]float64, len(items))
+		for id := range items {
+			expected[id] = &[2][quadraturePoints]float64{}
+		}
+
+		// E step, expected number of persons and of right answers at every quadrature point
+		for _, person := range persons {
+			posterior := make([]float64, len(q.points))
+			total := 0.0
+			for k, theta := range q.points {
+				likelihood := q.weights[k]
+				for _, a := range byPerson[person] {
+					p := items[a.Item].Probability(theta)
+					if a.Correct {
+						likelihood *= p
+					} else {
+						likelihood *= 1 - p
+					}
+				}
+				posterior[k] = likelihood
+				total += likelihood
+			}
+			for _, a := range byPerson[person] {
+				counts := expected[a.Item]
+				for k := range q.points {
+					share := posterior[k] / total
+					counts[0][k] += share
+					if a.Correct {
+						counts[1][k] += share
+					}
+				}
+			}
+		}
+
+		// M step, one item at a time
+		change := 0.0
+		for id, item := range items {
+			updated := maximize(item, q.points, expected[id][0][:], expected[id][1][:])
+			change = math.Max(change, math.Max(math.Abs(updated.A-item.A), math.Abs(updated.B-item.B)))
+			items[id] = updated
+		}
+		if change < 1e-4 {
+			break
+		}
+	}
+	return items
+}
+
+// maximize runs Newton-Raphson on the expected log likelihood of an item, parametrized as the logit
+// a*theta + c. A light ridge penalty pulls towards a=1 and b=0, keeping items answered by everyone or by
+// nobody from drifting to infinity.
+func maximize(item Item, points, n, r []float64) Item {
+	const ridge = 0.1
+	a, c := item.A, -item.A*item.B
+	for step := 0; step < 10; step++ {
+		ga, gc := -ridge*(a-1), -ridge*c
+		haa, hac, hcc := -ridge, 0.0, -ridge
+		for k, theta := range points {
+			p := 1 / (1 + math.Exp(-(a*theta + c)))
+			residual := r[k] - n[k]*p
+			ga += residual * theta
+			gc += residual
+			w := n[k] * p * (1 - p)
+			haa -= w * theta * theta
+			hac -= w * theta
+			hcc -= w
+		}
+		det := haa*hcc - hac*hac
+		if det == 0 {
+			break
+		}
+		da := (hcc*ga - hac*gc) / det
+		dc := (haa*gc - hac*ga) / det
+		a -= da
+		c -= dc
+		a = math.Min(math.Max(a, minDiscrimination), maxDiscrimination)
+		if math.Abs(da) < 1e-6 && math.Abs(dc) < 1e-6 {
+			break
+		}
+	}
+	b := math.Min(math.Max(-c/a, -maxDifficulty), maxDifficulty)
+	return Item{A: a, B: b}
+}
@@ -0,0 +1,144 @@
+package irt
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// simulate answers of persons with abilities drawn from a standard normal to the given items
+func simulate(rng *rand.Rand, items []Item, persons int) ([]Answer, []float64) {
+	abilities := make([]float64, persons)
+	var answers []Answer
+	for p := range abilities {
+		abilities[p] = rng.NormFloat64()
+		for i, item := range items {
+			answers = append(answers, Answer{Person: fmt.Sprintf("person-%d", p), Item: i, Correct: rng.Float64() < item.Probability(abilities[p])})
+		}
+	}
+	return answers, abilities
+}
+
+func correlation(x, y []float64) float64 {
+	mx, my := 0.0, 0.0
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(len(x))
+	my /= float64(len(y))
+	sxy, sxx, syy := 0.0, 0.0, 0.0
+	for i := range x {
+		sxy += (x[i] - mx) * (y[i] - my)
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+func bank(rng *rand.Rand, size int) []Item {
+	items := make([]Item, size)
+	for i := range items {
+		items[i] = Item{A: 0.8 + rng.Float64()*1.2, B: -2 + 4*rng.Float64()}
+	}
+	return items
+}
+
+func TestCalibrate_recoversSimulatedItems(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	truth := bank(rng, 15)
+	answers, _ := simulate(rng, truth, 1000)
+
+	calibrated := Calibrate(answers, 10, 100)
+	if len(calibrated) != len(truth) {
+		t.Fatalf("calibrated items, %d, did not match expected %d", len(calibrated), len(truth))
+	}
+
+	var trueA, estA, trueB, estB []float64
+	for i, item := range truth {
+		trueA, estA = append(trueA, item.A), append(estA, calibrated[i].A)
+		trueB, estB = append(trueB, item.B), append(estB, calibrated[i].B)
+		if math.Abs(calibrated[i].B-item.B) > 0.35 {
+			t.Errorf("difficulty of item %d, %.2f, too far from true %.2f", i, calibrated[i].B, item.B)
+		}
+	}
+	if r := correlation(trueB, estB); r < 0.98 {
+		t.Errorf("difficulty recovery correlation, %.3f, too low", r)
+	}
+	if r := correlation(trueA, estA); r < 0.8 {
+		t.Errorf("discrimination recovery correlation, %.3f, too low", r)
+	}
+}
+
+func TestCalibrate_skipsItemsWithFewResponses(t *testing.T) {
+	answers := []Answer{{Person: "a", Item: 1, Correct: true}, {Person: "b", Item: 1}, {Person: "a", Item: 2, Correct: true}}
+	calibrated := Calibrate(answers, 2, 10)
+	if _, ok := calibrated[2]; ok {
+		t.Errorf("item with too few responses should not be calibrated")
+	}
+	if _, ok := calibrated[1]; !ok {
+		t.Errorf("item with enough responses should be calibrated")
+	}
+}
+
+func TestEstimateAbility(t *testing.T) {
+	items := []Item{{A: 1.5, B: -1}, {A: 1.5, B: 0}, {A: 1.5, B: 1}}
+
+	low, _ := EstimateAbility(items, []bool{false, false, false})
+	mid, _ := EstimateAbility(items, []bool{true, false, false})
+	high, se := EstimateAbility(items, []bool{true, true, true})
+	if !(low < mid && mid < high) {
+		t.Errorf("abilities should grow with right answers, got %.2f, %.2f, %.2f", low, mid, high)
+	}
+	if math.IsInf(high, 0) || math.IsNaN(high) || se <= 0 || se >= 1 {
+		t.Errorf("all right answers should give a finite estimate with shrinking error, got %.2f ± %.2f", high, se)
+	}
+
+	prior, priorSE := EstimateAbility(nil, nil)
+	if math.Abs(prior) > 1e-9 || math.Abs(priorSE-1) > 0.01 {
+		t.Errorf("no answers should give the prior, got %.2f ± %.2f", prior, priorSE)
+	}
+}
+
+func TestAdaptive_simulatedCandidates(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+	items := bank(rng, 200)
+	const threshold, maxItems = 0.35, 40
+
+	var truths, estimates []float64
+	var lengths int
+	for c := 0; c < 100; c++ {
+		ability := rng.NormFloat64()
+		available := append([]Item{}, items...)
+		var asked []Item
+		var answers []bool
+		theta, se := EstimateAbility(nil, nil)
+		for se >= threshold && len(asked) < maxItems {
+			next := MostInformative(theta, available)
+			asked = append(asked, available[next])
+			answers = append(answers, rng.Float64() < available[next].Probability(ability))
+			available = append(available[:next], available[next+1:]...)
+			theta, se = EstimateAbility(asked, answers)
+		}
+		truths, estimates = append(truths, ability), append(estimates, theta)
+		lengths += len(asked)
+	}
+
+	if r := correlation(truths, estimates); r < 0.9 {
+		t.Errorf("adaptive estimates correlation with true abilities, %.3f, too low", r)
+	}
+	if mean := float64(lengths) / 100; mean >= maxItems {
+		t.Errorf("adaptive tests should stop on the standard error before the max length, mean length %.1f", mean)
+	}
+}
+
+func TestMostInformative(t *testing.T) {
+	items := []Item{{A: 1, B: -2}, {A: 1, B: 0.1}, {A: 1, B: 2}}
+	if got := MostInformative(0, items); got != 1 {
+		t.Errorf("most informative item, %d, did not match expected 1", got)
+	}
+	if got := MostInformative(0, nil); got != -1 {
+		t.Errorf("most informative of no items, %d, did not match expected -1", got)
+	}
+}
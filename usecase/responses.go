@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/togglhire/backend-homework/domain"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+type Responses struct {
+	questions domain.QuestionRepository
+	repo      domain.ResponseRepository
+}
+
+func NewResponses(questionRepository domain.QuestionRepository, responseRepository domain.ResponseRepository) Responses {
+	return Responses{questions: questionRepository, repo: responseRepository}
+}
+
+// CandidateView returns the question as a candidate sees it, the result is only included once the candidate
+// has submitted a response.
+func (r Responses) CandidateView(ctx context.Context, questionID int, candidate string) (_ domain.CandidateQuestion, err error) {
+	ctx, end := startSpan(ctx, "Responses.CandidateView", attribute.Int("question.id", questionID))
+	defer end(&err)
+
+	question, err := r.questions.Get(ctx, questionID)
+	if err != nil {
+		return domain.CandidateQuestion{}, fmt.Errorf("err getting question:%w", err)
+	}
+
+	view := domain.NewCandidateQuestion(question)
+	if candidate == "" {
+		return view, nil
+	}
+
+	response, err := r.repo.GetResponse(questionID, candidate)
+	if errors.Is(err, domain.ErrNoResponseFound) {
+		return view, nil
+	}
+	if err != nil {
+		return domain.CandidateQuestion{}, fmt.Errorf("err getting response:%w", err)
+	}
+
+	result := domain.NewResult(question, response)
+	view.Result = &result
+	return view, nil
+}
+
+func (r Responses) Submit(ctx context.Context, response domain.Response) (_ domain.Result, err error) {
+	ctx, end := startSpan(ctx, "Responses.Submit")
+	defer end(&err)
+
+	question, response, err := r.record(ctx, response)
+	if err != nil {
+		return domain.Result{}, err
+	}
+	return domain.NewResult(question, response), nil
+}
+
+// record grades and stores the response, returning the question it answers.
+func (r Responses) record(ctx context.Context, response domain.Response) (domain.Question, domain.Response, error) {
+	question, response, err := r.grade(ctx, response)
+	if err != nil {
+		return domain.Question{}, domain.Response{}, err
+	}
+
+	response, err = r.repo.AddResponse(response)
+	if err != nil {
+		return domain.Question{}, domain.Response{}, fmt.Errorf("err adding response:%w", err)
+	}
+	return question, response, nil
+}
+
+// grade marks the response correct or not against the question it answers, without storing it.
+func (r Responses) grade(ctx context.Context, response domain.Response) (domain.Question, domain.Response, error) {
+	question, err := r.questions.Get(ctx, response.QuestionID)
+	if err != nil {
+		return domain.Question{}, domain.Response{}, fmt.Errorf("err getting question:%w", err)
+	}
+
+	response.Selected = uniqueSorted(response.Selected)
+	response.Correct, err = domain.Grade(question, response.Selected)
+	if err != nil {
+		return domain.Question{}, domain.Response{}, err
+	}
+	response.CreatedAt = time.Now().UTC()
+	return question, response, nil
+}
+
+func uniqueSorted(values []int) []int {
+	seen := make(map[int]bool, len(values))
+	unique := make([]int, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
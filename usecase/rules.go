@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/togglhire/backend-homework/domain"
+)
+
+// ValidationRules holds the business rules of an organization, the rules of the registry it turned on on top
+// of the ones every organization follows.
+type ValidationRules struct {
+	repo     domain.RuleRepository
+	registry domain.RuleRegistry
+}
+
+func NewValidationRules(ruleRepository domain.RuleRepository, registry domain.RuleRegistry) ValidationRules {
+	return ValidationRules{repo: ruleRepository, registry: registry}
+}
+
+func (v ValidationRules) Settings(ctx context.Context) (_ []domain.RuleSetting, err error) {
+	ctx, end := startSpan(ctx, "ValidationRules.Settings")
+	defer end(&err)
+
+	return v.repo.GetRuleSettings(ctx)
+}
+
+// Save refuses settings naming rules the registry does not have or with params the rule can not work with.
+func (v ValidationRules) Save(ctx context.Context, settings []domain.RuleSetting) (err error) {
+	ctx, end := startSpan(ctx, "ValidationRules.Save")
+	defer end(&err)
+
+	if _, err := v.registry.Build(settings); err != nil {
+		return err
+	}
+	if err := v.repo.SaveRuleSettings(ctx, settings); err != nil {
+		return fmt.Errorf("err saving rule settings:%w", err)
+	}
+	return nil
+}
+
+// Available lists the names of the rules organizations can turn on.
+func (v ValidationRules) Available() []string {
+	return v.registry.Names()
+}
+
+// Check returns domain.Violations listing every rule the question breaks, nil when it breaks none.
+func (v ValidationRules) Check(ctx context.Context, question domain.Question) (err error) {
+	ctx, end := startSpan(ctx, "ValidationRules.Check")
+	defer end(&err)
+
+	settings, err := v.repo.GetRuleSettings(ctx)
+	if err != nil {
+		return fmt.Errorf("err getting rule settings:%w", err)
+	}
+	configured, err := v.registry.Build(settings)
+	if err != nil {
+		return fmt.Errorf("err building rules:%w", err)
+	}
+
+	rules := append(domain.Rules{domain.OneCorrectOption()}, configured...)
+	if violations := rules.Check(question); len(violations) > 0 {
+		return domain.Violations(violations)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const TRACER_NAME = "github.com/togglhire/backend-homework/usecase"
+
+// tracer is the one of the provider installed last, see server.tracer.
+func tracer() trace.Tracer {
+	return otel.Tracer(TRACER_NAME)
+}
+
+// startSpan traces a usecase call, the returned func ends the span with the error the call returns, if any:
+//
+//	ctx, end := startSpan(ctx, "Questions.Update")
+//	defer end(&err)
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, func(*error)) {
+	ctx, span := tracer().Start(ctx, name, trace.WithAttributes(attributes...))
+	return ctx, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/togglhire/backend-homework/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/text/language"
+)
+
+// ListLocalized returns the questions translated to the first locale of the fallback chain they have a
+// translation for, questions without any keep their source language.
+func (q Questions) ListLocalized(ctx context.Context, filter domain.QuestionFilter, locales []string) (_ []domain.Question, err error) {
+	ctx, end := startSpan(ctx, "Questions.ListLocalized")
+	defer end(&err)
+
+	questions, err := q.List(ctx, filter)
+	if err != nil || len(locales) == 0 {
+		return questions, err
+	}
+
+	translations, err := q.translations.GetTranslationsByLocales(ctx, locales)
+	if err != nil {
+		return nil, fmt.Errorf("err getting translations:%w", err)
+	}
+
+	for i, question := range questions {
+		questions[i], _ = localize(question, translations[question.ID], locales)
+	}
+	return questions, nil
+}
+
+// GetLocalized returns the question translated following the locales fallback chain, along with the
+// locale picked, empty when the source language is used.
+func (q Questions) GetLocalized(ctx context.Context, id int, locales []string) (_ domain.Question, _ string, err error) {
+	ctx, end := startSpan(ctx, "Questions.GetLocalized", attribute.Int("question.id", id))
+	defer end(&err)
+
+	question, err := q.Get(ctx, id)
+	if err != nil || len(locales) == 0 {
+		return question, "", err
+	}
+
+	translations, err := q.translations.GetTranslations(ctx, id)
+	if err != nil {
+		return domain.Question{}, "", fmt.Errorf("err getting translations:%w", err)
+	}
+
+	question, locale := localize(question, translations, locales)
+	return question, locale, nil
+}
+
+func (q Questions) GetTranslations(ctx context.Context, id int) (_ []domain.Translation, err error) {
+	ctx, end := startSpan(ctx, "Questions.GetTranslations", attribute.Int("question.id", id))
+	defer end(&err)
+
+	if _, err := q.Get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	translations, err := q.translations.GetTranslations(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("err getting translations:%w", err)
+	}
+	return translations, nil
+}
+
+// SaveTranslation refuses translations whose question, once translated, breaks the rules of the organization.
+func (q Questions) SaveTranslation(ctx context.Context, id int, translation domain.Translation) (_ domain.Translation, err error) {
+	ctx, end := startSpan(ctx, "Questions.SaveTranslation", attribute.Int("question.id", id))
+	defer end(&err)
+
+	question, err := q.Get(ctx, id)
+	if err != nil {
+		return domain.Translation{}, err
+	}
+	if !translation.Matches(question) {
+		return domain.Translation{}, domain.ErrTranslationMismatch
+	}
+	if err := q.rules.Check(ctx, translation.Apply(question)); err != nil {
+		return domain.Translation{}, fmt.Errorf("err saving translation:%w", err)
+	}
+
+	translation.Locale = CanonicalLocale(translation.Locale)
+	if err := q.translations.SaveTranslation(ctx, id, translation); err != nil {
+		return domain.Translation{}, fmt.Errorf("err saving translation:%w", err)
+	}
+	return translation, nil
+}
+
+func (q Questions) DeleteTranslation(ctx context.Context, id int, locale string) (err error) {
+	ctx, end := startSpan(ctx, "Questions.DeleteTranslation", attribute.Int("question.id", id))
+	defer end(&err)
+
+	if err := q.translations.DeleteTranslation(ctx, id, CanonicalLocale(locale)); err != nil {
+		return fmt.Errorf("err deleting translation:%w", err)
+	}
+	return nil
+}
+
+// CanonicalLocale normalizes a BCP 47 tag so that "en-us" and "en-US" are stored and looked up the same way.
+func CanonicalLocale(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	return tag.String()
+}
+
+func localize(question domain.Question, translations []domain.Translation, locales []string) (domain.Question, string) {
+	for _, locale := range locales {
+		for _, translation := range translations {
+			if translation.Locale == locale && translation.Matches(question) {
+				return translation.Apply(question), locale
+			}
+		}
+	}
+	return question, ""
+}
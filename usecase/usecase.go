@@ -1,43 +1,146 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/togglhire/backend-homework/domain"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Questions struct {
-	repo domain.QuestionRepository
+	repo         domain.QuestionRepository
+	translations domain.TranslationRepository
+	attachments  Attachments
+	rules        ValidationRules
 }
 
-func NewQuestions(questionRepository domain.QuestionRepository) Questions {
-	return Questions{repo: questionRepository}
+func NewQuestions(questionRepository domain.QuestionRepository, translationRepository domain.TranslationRepository, attachments Attachments, rules ValidationRules) Questions {
+	return Questions{repo: questionRepository, translations: translationRepository, attachments: attachments, rules: rules}
 }
 
-func (q Questions) GetAll() []domain.Question {
-	questions, err := q.repo.GetAll()
+func (q Questions) GetAll(ctx context.Context) (_ []domain.Question, err error) {
+	ctx, end := startSpan(ctx, "Questions.GetAll")
+	defer end(&err)
+
+	questions, err := q.repo.GetAll(ctx)
 	if err != nil {
-		log.Printf("err getting all questions: %s", err)
-		return []domain.Question{}
+		return nil, fmt.Errorf("err getting all questions:%w", err)
 	}
 
-	return questions
+	return questions, nil
+}
+
+func (q Questions) List(ctx context.Context, filter domain.QuestionFilter) (_ []domain.Question, err error) {
+	ctx, end := startSpan(ctx, "Questions.List")
+	defer end(&err)
+
+	questions, err := q.repo.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("err listing questions:%w", err)
+	}
 
+	return questions, nil
 }
 
-func (q Questions) Add(question domain.Question) error {
-	err := q.repo.Add(question)
+// Summary adds up the estimated time and points of a set of questions, all of them must exist.
+func (q Questions) Summary(ctx context.Context, ids []int) (_ domain.Summary, err error) {
+	ctx, end := startSpan(ctx, "Questions.Summary")
+	defer end(&err)
+
+	questions, err := q.repo.Find(ctx, domain.QuestionFilter{IDs: ids})
+	if err != nil {
+		return domain.Summary{}, fmt.Errorf("err getting questions for summary:%w", err)
+	}
+
+	unique := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	if len(questions) != len(unique) {
+		return domain.Summary{}, domain.ErrNoQuestionFound
+	}
+
+	summary := domain.Summary{Questions: len(questions)}
+	for _, question := range questions {
+		summary.TotalEstimatedSeconds += question.EstimatedSeconds
+		summary.TotalPoints += question.Points
+	}
+	return summary, nil
+}
+
+func (q Questions) Get(ctx context.Context, id int) (_ domain.Question, err error) {
+	ctx, end := startSpan(ctx, "Questions.Get", attribute.Int("question.id", id))
+	defer end(&err)
+
+	question, err := q.repo.Get(ctx, id)
+	if err != nil {
+		return domain.Question{}, fmt.Errorf("err getting question:%w", err)
+	}
+	return question, nil
+}
+
+// Validate checks the business rules of the organization, see ValidationRules.Check.
+func (q Questions) Validate(ctx context.Context, question domain.Question) error {
+	return q.rules.Check(ctx, question)
+}
+
+// Add refuses questions breaking the rules of the organization, whichever way they come in.
+func (q Questions) Add(ctx context.Context, question domain.Question) (err error) {
+	ctx, end := startSpan(ctx, "Questions.Add")
+	defer end(&err)
+
+	if err := q.rules.Check(ctx, question); err != nil {
+		return fmt.Errorf("err adding question:%w", err)
+	}
+
+	err = q.repo.Add(ctx, question)
 	if err != nil {
 		return fmt.Errorf("err adding question:%w", err)
 	}
 	return nil
 }
 
-func (q Questions) Update(question domain.Question) error {
-	err := q.repo.Update(question)
+func (q Questions) Update(ctx context.Context, question domain.Question) (err error) {
+	ctx, end := startSpan(ctx, "Questions.Update")
+	defer end(&err)
+
+	if err := q.rules.Check(ctx, question); err != nil {
+		return fmt.Errorf("err updating question:%w", err)
+	}
+
+	previous, err := q.repo.Update(ctx, question)
 	if err != nil {
 		return fmt.Errorf("err updating question:%w", err)
 	}
+
+	q.releaseAttachments(previous)
+	return nil
+}
+
+func (q Questions) Delete(ctx context.Context, id int) (err error) {
+	ctx, end := startSpan(ctx, "Questions.Delete", attribute.Int("question.id", id))
+	defer end(&err)
+
+	previous, err := q.repo.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("err deleting question:%w", err)
+	}
+
+	err = q.repo.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("err deleting question:%w", err)
+	}
+
+	q.releaseAttachments(previous)
 	return nil
 }
+
+// releaseAttachments cleans up the attachments a question used to point to, failing here must not fail
+// the mutation that already happened.
+func (q Questions) releaseAttachments(question domain.Question) {
+	if err := q.attachments.Release(question.AttachmentIDs()); err != nil {
+		slog.Error("err releasing attachments", "question", question.ID, "err", err)
+	}
+}